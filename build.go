@@ -2,13 +2,22 @@ package excelorm
 
 import (
 	"bytes"
+	"database/sql/driver"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/xuri/excelize/v2"
 )
 
 type Option func(*options)
@@ -65,78 +74,93 @@ type Option func(*options)
 func WriteExcelSaveAs(fileName string, sheetModels []SheetModel, opts ...Option) error {
 	time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
 	if fileName == "" {
-		return errors.New("fileName can not be empty")
+		return ErrEmptyFileName
 	}
 	f, err := write(sheetModels, opts...)
-	if err != nil {
+	if err != nil && !isRowErrors(err) {
 		return err
 	}
-	return f.SaveAs(fileName)
+	defer f.Close() // remove temp files of stream writers
+	if saveErr := f.SaveAs(fileName); saveErr != nil {
+		return saveErr
+	}
+	return err // rows skipped by WithContinueOnError
 }
 
 func write(sheetModels []SheetModel, opts ...Option) (*excelize.File, error) {
-	// default options
-	options := &options{
-		timeFormatLayout: "2006-01-02 15:04:05",
-		floatPrecision:   2,
-		floatFmt:         'f',
-		ifNullValue:      "",
-	}
+	return writeFile(excelize.NewFile(), false, sheetModels, opts...)
+}
 
-	// apply options
-	for _, opt := range opts {
-		opt(options)
+// writeFile writes sheetModels into f, f is a template workbook if template is true,
+// its sheets are kept even they are not written
+func writeFile(f *excelize.File, template bool, sheetModels []SheetModel, opts ...Option) (*excelize.File, error) {
+	builder, err := newBuilder(f, template, opts...)
+	if err != nil {
+		return nil, err
 	}
-
-	f := excelize.NewFile()
-	sheetLinesCount := make(map[string]int)
-	for _, sheetModel := range sheetModels {
-		if sheetModel == nil {
-			return nil, errors.New("nil reference row append is not allowed")
-		}
-		sheetName := sheetModel.SheetName()
-		if sheetName == "" {
-			return nil, errors.New("sheetModel must have a sheet name")
+	builder.Append(sheetModels...)
+	if err = builder.finish(); err != nil {
+		if isRowErrors(err) {
+			return f, err // the workbook is written without the skipped rows
 		}
+		return nil, err
+	}
+	return f, nil
+}
 
-		modelKind := reflect.TypeOf(sheetModel).Kind()
-		switch modelKind {
-		case reflect.Struct:
-			l := sheetLinesCount[sheetName]
-			err := appendRow(f, sheetModel, l, options)
-			if err != nil {
-				return nil, err
-			}
-			sheetLinesCount[sheetName]++
-			if l == 0 && !options.headless { // first line is header, so counter increase again
-				sheetLinesCount[sheetName]++
-			}
-		default:
-			return nil, errors.New("sheetModel must be struct")
-		}
+// setSheets applies the options of written sheets, they are already applied by stream writers
+func setSheets(f *excelize.File, options *options) error {
+	if options.streaming {
+		return flushStreams(options)
 	}
-	err := setNoDataSheetHeaders(f, options)
-	if err != nil {
-		return nil, err
+	if err := setSummaryRows(f, options); err != nil {
+		return err
 	}
-	// delete default sheet
-	var containsModelSheetNameEqSheet1 bool
-	for _, sheetModel := range sheetModels {
-		if sheetModel.SheetName() == "Sheet1" {
-			containsModelSheetNameEqSheet1 = true
-			break
-		}
+	if err := autoFitColumns(f, options); err != nil {
+		return err
 	}
-	for _, sheetModel := range options.sheetHeaders {
-		if sheetModel.SheetName() == "Sheet1" {
-			containsModelSheetNameEqSheet1 = true
-			break
-		}
+	if err := setConditionalFormats(f, options); err != nil {
+		return err
+	}
+	if err := addCharts(f, options); err != nil {
+		return err
 	}
-	if !containsModelSheetNameEqSheet1 {
-		f.DeleteSheet("Sheet1")
+	if err := addColumnValidations(f, options); err != nil {
+		return err
 	}
-	return f, nil
+	if err := setPanes(f, options); err != nil {
+		return err
+	}
+	if err := setAutoFilters(f, options); err != nil {
+		return err
+	}
+	if err := setRowHeights(f, options); err != nil {
+		return err
+	}
+	if err := setPageSetups(f, options); err != nil {
+		return err
+	}
+	if err := setSheetBackgrounds(f, options); err != nil {
+		return err
+	}
+	if err := protectSheets(f, options); err != nil {
+		return err
+	}
+	return setSheetViews(f, options)
+}
+
+// newSheet creates sheet named sheetName, excelize compares sheet names case-insensitively,
+// so the default sheet "Sheet1" is renamed to sheetName instead if they are the same
+func newSheet(f *excelize.File, sheetName string) error {
+	idx, err := f.GetSheetIndex(sheetName)
+	if err != nil {
+		return err
+	}
+	if idx == -1 {
+		_, err = f.NewSheet(sheetName)
+		return err
+	}
+	return f.SetSheetName(f.GetSheetName(idx), sheetName)
 }
 
 func setNoDataSheetHeaders(f *excelize.File, options *options) error {
@@ -145,13 +169,14 @@ func setNoDataSheetHeaders(f *excelize.File, options *options) error {
 		return nil
 	}
 	for _, model := range models {
-		sheetName := model.SheetName()
-		idx := f.GetSheetIndex(sheetName)
-		if idx != 0 {
+		sheetName, err := getSheetName(model, options)
+		if err != nil {
+			return err
+		}
+		if _, ok := options.sheetLayouts[sheetName]; ok {
 			// sheet exists, continue
 			continue
 		}
-		f.NewSheet(sheetName)
 
 		// check if sheetModel is pointer
 		if reflect.TypeOf(model).Kind() == reflect.Ptr {
@@ -160,59 +185,384 @@ func setNoDataSheetHeaders(f *excelize.File, options *options) error {
 				// if type(model) is SheetModel, then *model is still SheetModel
 				model = reflect.Indirect(reflect.ValueOf(model)).Interface().(SheetModel)
 			} else {
-				return errors.New("nil reference row append is not allowed")
+				return ErrNilRow
 			}
 		}
 
-		modelType := reflect.TypeOf(model)
-		for i := 0; i < modelType.NumField(); i++ {
-			field := modelType.Field(i)
-			header := field.Tag.Get("excel_header")
-			if header == "" { // if no excel_header tag, use field name as header
-				header = field.Name
-			} else if header == "-" {
-				continue // skip this field if header is "-"
-			}
-			cellName, err := coordinatesToCellName(i+1, 1)
-			if err != nil {
-				return err
-			}
-			f.SetCellValue(sheetName, cellName, header) // set header
+		sheetOptions := modelOptions(sheetName, model, options)
+		modelType := valueOfModel(model).Type()
+		columns, err := parseColumns(modelType, sheetOptions)
+		if err != nil {
+			return err
+		}
+		if _, err = newSheetLayout(f, sheetName, modelType, columns, true, sheetOptions); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// WriteExcelIntoTemplate 打开预先设置好样式的模板文件 templatePath, 将数据写入其中并保存为 bytes.Buffer, 用法同 WriteExcelSaveAs,
+// 模板中已有的格式, 图表和宏(.xlsm)保持不变, sheet 存在时写入该 sheet, 否则新建 sheet,
+// 数据默认从 A1 开始写入, 可以通过 WithAnchorCell 为每个 sheet 指定起始单元格; WithSheetOrder 仅对新建的 sheet 生效,
+// 模板中的占位符见 WithPlaceholders
+func WriteExcelIntoTemplate(templatePath string, sheetModels []SheetModel, opts ...Option) (*bytes.Buffer, error) {
+	f, err := excelize.OpenFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err = writeFile(f, true, sheetModels, opts...); err != nil && !isRowErrors(err) {
+		return nil, err
+	}
+	buffer := new(bytes.Buffer)
+	if writeErr := f.Write(buffer); writeErr != nil {
+		return nil, writeErr
+	}
+	return buffer, err // rows skipped by WithContinueOnError
+}
+
+// WithAnchorCell sheet 中表格(标题行, 表头和数据)左上角的单元格, 如 "B5", 默认为 "A1", 常用于 WriteExcelIntoTemplate
+func WithAnchorCell(sheet, cell string) Option {
+	return func(options *options) {
+		if options.anchorCells == nil {
+			options.anchorCells = make(map[string]string)
+		}
+		options.anchorCells[sanitizeSheetName(sheet)] = cell
+	}
+}
+
 // WriteExcelAsBytesBuffer 生成excel并保存为 bytes.Buffer, 用法同 WriteExcelSaveAs
 func WriteExcelAsBytesBuffer(sheetModels []SheetModel, opts ...Option) (*bytes.Buffer, error) {
 	buffer := new(bytes.Buffer)
 	f, err := write(sheetModels, opts...)
-	if err != nil {
+	if err != nil && !isRowErrors(err) {
 		return nil, err
 	}
-	err = f.Write(buffer)
-	if err != nil {
-		return nil, err
+	defer f.Close() // remove temp files of stream writers
+	if writeErr := f.Write(buffer); writeErr != nil {
+		return nil, writeErr
+	}
+	return buffer, err // rows skipped by WithContinueOnError
+}
+
+// ValidateModels 按写入的流程校验 sheetModels 和 opts(如 tag, 字段类型, sheet 名称, 选项引用的 sheet 和表头), 但不生成文件,
+// 返回的错误与 WriteExcelSaveAs 相同, 可以在测试中提前发现不支持的字段类型等问题
+func ValidateModels(sheetModels []SheetModel, opts ...Option) error {
+	f, err := write(sheetModels, opts...)
+	if f != nil {
+		_ = f.Close() // remove temp files of stream writers
 	}
-	return buffer, nil
+	return err
 }
 
 type SheetModel interface {
 	SheetName() string
 }
 
+// SheetModelWithOptions 数据模型实现该接口后, SheetOptions 返回的选项仅对该模型所在的 sheet 生效, 且优先于调用时传入的选项,
+// 使按 sheet 的设置(如 WithHeadless, WithAutoFitColumns)可以与模型定义在一起, 而不必在每次调用时传入;
+// 以第一个写入该 sheet 的模型为准, 工作簿级别的选项(如 WithDefaultFont 的字体, WithSheetOrder)不生效
+// example usage:
+//
+//	func (Foo) SheetOptions() []excelorm.Option {
+//		return []excelorm.Option{excelorm.WithFreezeHeader(), excelorm.WithAutoFitColumns()}
+//	}
+type SheetModelWithOptions interface {
+	SheetModel
+	SheetOptions() []Option
+}
+
+// modelOptions returns options of sheet which model belongs to, with options of SheetModelWithOptions applied
+func modelOptions(sheetName string, model SheetModel, options *options) *options {
+	if sheetOptions, ok := options.sheetOptions[sheetName]; ok {
+		return sheetOptions
+	}
+	withOptions, ok := valueAs(reflect.ValueOf(model), sheetModelWithOptionsType)
+	if !ok {
+		return options
+	}
+	sheetOptions := options.clone()
+	for _, opt := range withOptions.(SheetModelWithOptions).SheetOptions() {
+		opt(sheetOptions)
+	}
+	// rules are applied after all, they are selected by sheet
+	options.conditionalRules = sheetOptions.conditionalRules
+	options.charts = sheetOptions.charts
+	options.columnValidations = sheetOptions.columnValidations
+	options.setSheetOptions(sheetName, sheetOptions)
+	return sheetOptions
+}
+
+func (o *options) setSheetOptions(sheetName string, sheetOptions *options) {
+	if o.sheetOptions == nil {
+		o.sheetOptions = make(map[string]*options)
+	}
+	o.sheetOptions[sheetName] = sheetOptions
+}
+
+// forSheet returns options of sheet, see SheetModelWithOptions
+func (o *options) forSheet(sheetName string) *options {
+	if sheetOptions, ok := o.sheetOptions[sheetName]; ok {
+		return sheetOptions
+	}
+	return o
+}
+
+// clone returns a copy of o for a single sheet, the states of the writing are shared,
+// as well as the options selected by sheet name, such as WithFreezePanes
+func (o *options) clone() *options {
+	// make the maps of options selected by sheet name to share them
+	if o.freezePanes == nil {
+		o.freezePanes = make(map[string]string)
+	}
+	if o.summaryRows == nil {
+		o.summaryRows = make(map[string]map[string]Aggregate)
+	}
+	if o.pageSetups == nil {
+		o.pageSetups = make(map[string]PageSetup)
+	}
+	if o.sheetProtections == nil {
+		o.sheetProtections = make(map[string]sheetProtection)
+	}
+	if o.sheetTitles == nil {
+		o.sheetTitles = make(map[string]sheetTitle)
+	}
+	if o.sheetBackgrounds == nil {
+		o.sheetBackgrounds = make(map[string]string)
+	}
+	if o.sortKeys == nil {
+		o.sortKeys = make(map[string][]sortKey)
+	}
+	c := *o
+	c.valueMappings = make(map[string]map[interface{}]string, len(o.valueMappings))
+	for header, mapping := range o.valueMappings {
+		c.valueMappings[header] = mapping
+	}
+	c.conditionalRules = o.conditionalRules[:len(o.conditionalRules):len(o.conditionalRules)] // copy on append
+	c.charts = o.charts[:len(o.charts):len(o.charts)]
+	c.columnValidations = o.columnValidations[:len(o.columnValidations):len(o.columnValidations)]
+	// columns depend on options such as headerSeparator, parse them again
+	c.columnPlans = nil
+	return &c
+}
+
+// generatedSheetOptions returns a copy of o for the sheets generated by excelorm, such as the summary sheet and the errors sheet,
+// the options of data rows are cleared, their callbacks and mappings expect the models of the caller
+func (o *options) generatedSheetOptions() *options {
+	c := o.clone()
+	c.cellStyleFunc, c.rowStyleFunc, c.rowGroupFunc = nil, nil, nil
+	c.beforeRowHook, c.rowFilter, c.afterRowHook, c.sheetPartitioner = nil, nil, nil, nil
+	c.valueMappings, c.deduplicateBy, c.headerComments = nil, nil, nil
+	c.rowNumberHeader = ""
+	c.checkboxes, c.checkboxHeaders = false, nil
+	return c
+}
+
+// CellMarshaler 自定义类型实现该接口后, 由 MarshalExcelCell 的返回值决定单元格内容,
+// 优先于内置的类型处理及 encoding.TextMarshaler, 返回 nil 时展示为 WithIfNullValue 设置的空值
+type CellMarshaler interface {
+	MarshalExcelCell() (interface{}, error)
+}
+
+// RowStyler 数据模型实现该接口后, 由 RowStyle 的返回值决定整行单元格的样式, 如已取消的订单置灰,
+// 返回 nil 时使用默认样式, 优先级低于 WithRowStyleFunc 和 WithCellStyleFunc
+type RowStyler interface {
+	RowStyle() *excelize.Style
+}
+
+// RowGrouper 数据模型实现该接口后, 由 GroupLevel 的返回值决定行的分级显示(大纲)级别, 范围为 0 至 7, 0 为不分组,
+// 如 地区 -> 门店 -> 商品 的层级报表中商品行返回 2, 门店行返回 1, 优先级低于 WithRowGroupFunc
+type RowGrouper interface {
+	GroupLevel() int
+}
+
+// RowValidator 数据模型实现该接口后, 写入前调用 ValidateRow 校验数据, 返回错误时该行写入失败, 错误为包含行信息的 *RowError,
+// 与 WithContinueOnError 或 WithErrorSheet 同时使用时跳过该行, 使业务规则(如必填, 取值范围)可以与模型定义在一起
+type RowValidator interface {
+	ValidateRow() error
+}
+
+// decimalLike is implemented by decimal types such as github.com/shopspring/decimal.Decimal
+type decimalLike interface {
+	StringFixed(places int32) string
+}
+
+var (
+	cellMarshalerType         = reflect.TypeOf((*CellMarshaler)(nil)).Elem()
+	rowStylerType             = reflect.TypeOf((*RowStyler)(nil)).Elem()
+	rowGrouperType            = reflect.TypeOf((*RowGrouper)(nil)).Elem()
+	rowValidatorType          = reflect.TypeOf((*RowValidator)(nil)).Elem()
+	sheetModelWithOptionsType = reflect.TypeOf((*SheetModelWithOptions)(nil)).Elem()
+	decimalType               = reflect.TypeOf((*decimalLike)(nil)).Elem()
+	valuerType                = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType         = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType              = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
 type options struct {
-	timeFormatLayout string       // time.Time, *time.Time 的格式化版图
-	floatPrecision   int          // 小数保留多少位
-	floatFmt         byte         // 小数的格式，默认为'f',详细见 strconv.FormatFloat 的注释
-	ifNullValue      string       // null pointer		空值的默认显示
-	sheetHeaders     []SheetModel // 当没有数据时，表头的默认显示
-	trueValue        *string      // bool类型的true显示值
-	falseValue       *string      // bool类型的false显示值
-	integerAsString  bool         // int类型的字段是否以字符串形式显示(避免excel自动转为科学计数法)
-	headless         bool         // 是否显示表头
+	timeFormatLayout  string                            // time.Time, *time.Time 的格式化版图
+	floatPrecision    int                               // 小数保留多少位
+	floatFmt          byte                              // 小数的格式，默认为'f',详细见 strconv.FormatFloat 的注释
+	ifNullValue       string                            // null pointer		空值的默认显示
+	sheetHeaders      []SheetModel                      // 当没有数据时，表头的默认显示
+	trueValue         *string                           // bool类型的true显示值
+	falseValue        *string                           // bool类型的false显示值
+	integerAsString   bool                              // int类型的字段是否以字符串形式显示(避免excel自动转为科学计数法)
+	headless          bool                              // 是否显示表头
+	headerSeparator   string                            // 嵌套结构体的表头连接符, 默认为"."
+	dedupeHeaders     bool                              // 表头相同时是否添加序号后缀, 默认返回错误
+	sliceDelimiter    string                            // slice, array 类型元素的分隔符, 默认为", "
+	mapFormat         MapFormat                         // map 类型字段的展示格式, 默认不支持 map 类型
+	stringerFallback  bool                              // 非基础类型实现 fmt.Stringer 时使用 String() 展示
+	durationFormat    DurationFormat                    // time.Duration 类型字段的展示格式, 默认为 time.Duration.String()
+	decimalPlaces     *int32                            // decimal 类型保留多少位小数, 默认不做处理
+	bytesFormat       BytesFormat                       // []byte 类型字段的展示格式, 默认为 UTF-8 字符串
+	rawJSONIndent     string                            // json.RawMessage 类型字段格式化的缩进, 默认不格式化
+	rawJSONMaxLength  int                               // json.RawMessage 类型字段展示的最大字符数, 默认不截断
+	overflowPolicy    CellOverflowPolicy                // 单元格内容超过32767个字符时的处理方式
+	valueMappings     map[string]map[interface{}]string // 按表头指定的值与展示内容的映射
+	timeAsNativeDate  bool                              // time.Time 是否写为 excel 原生的日期时间单元格
+	timeNumberFormat  string                            // 原生日期时间单元格的数字格式
+	floatAsNumber     bool                              // float 是否写为 excel 原生的数字单元格
+	floatNumberFormat string                            // 原生数字单元格的数字格式
+	timeLocation      *time.Location                    // time.Time 展示前转换到的时区, 默认不转换
+	zeroTimeValue     *string                           // time.Time 零值的展示内容, 默认按时间格式展示
+	autoFitColumns    bool                              // 是否按内容自动调整列宽
+	maxColumnWidth    float64                           // 自动调整列宽时的最大列宽
+	zebraFillColor    string                            // 隔行填充的背景色, 默认不填充
+	styleIDs          map[cellStyle]int                 // 已创建的单元格样式, 写入时创建
+	sheetLayouts      map[string]*sheetLayout           // 已写入的 sheet 的布局, 写入时记录
+	columnPlans       map[reflect.Type][]column         // 已解析的数据类型的列, 写入时缓存
+	conditionalRules  []conditionalRule                 // 按表头设置的条件格式
+	freezeHeader      bool                              // 是否冻结表头行
+	freezePanes       map[string]string                 // 按 sheet 指定的冻结窗格左上角单元格
+	autoFilter        bool                              // 是否为表头添加筛选
+	cellStyleFunc     CellStyleFunc                     // 按单元格数据返回样式
+	rowStyleFunc      RowStyleFunc                      // 按行数据返回样式
+	rowGroupFunc      RowGroupFunc                      // 按行数据返回分级显示级别
+	summaryRows       map[string]map[string]Aggregate   // 按 sheet 指定的汇总行各列的汇总函数
+	tableBorders      bool                              // 是否为表格添加边框
+	borderStyle       int                               // 边框的线条样式
+	borderColor       string                            // 边框的颜色
+	fontName          string                            // 默认字体, 为空时为 excelize 的默认字体 Calibri
+	fontSize          float64                           // 默认字号, 为0时为 excelize 的默认字号11
+	rowHeight         float64                           // 数据行的行高, 为0时为默认行高
+	headerRowHeight   float64                           // 表头行的行高, 为0时为默认行高
+	pageSetups        map[string]PageSetup              // 按 sheet 指定的页面设置, key 为空时应用于所有 sheet
+	sheetProtections  map[string]sheetProtection        // 按 sheet 指定的保护设置
+	hideGridlines     bool                              // 是否隐藏网格线
+	gridlineSheets    []string                          // 隐藏网格线的 sheet, 为空时为所有 sheet
+	zoom              float64                           // 缩放比例, 为0时为默认缩放比例
+	zoomSheets        []string                          // 设置缩放比例的 sheet, 为空时为所有 sheet
+	sheetTitles       map[string]sheetTitle             // 按 sheet 指定的标题行
+	headerStyle       *excelize.Style                   // 表头单元格的样式, 由 WithTheme 设置
+	sheetOrder        []string                          // sheet 的顺序
+	strictSheetNames  bool                              // sheet 名称不合法时是否返回错误, 默认自动修正
+	autoSplitSheets   bool                              // sheet 超过最大行数时是否继续写入新的 sheet
+	maxRows           int                               // sheet 的最大行数, 默认为 excel 的最大行数
+	sheetParts        map[string]int                    // 按 sheet 记录自动拆分后的 sheet 数量
+	deduplicateBy     map[string][]string               // 按 sheet 指定的用于去重的列的表头
+	deduplicateKeys   map[string]map[string]bool        // 按 sheet 记录已写入的行的去重键, 写入成功后记录
+	sortKeys          map[string][]sortKey              // 按 sheet 指定的排序关键字
+	sheetOptions      map[string]*options               // 按 sheet 记录应用了 SheetModelWithOptions 的选项
+	sheetPartitioner  func(model SheetModel) string     // 按行返回 sheet 名称, 覆盖 SheetName()
+	docProperties     *docProperties                    // 工作簿的文档属性
+	activeSheet       string                            // 打开工作簿时显示的 sheet
+	hiddenSheets      []string                          // 隐藏的 sheet
+	definedNames      []definedName                     // 注册为名称的 sheet 数据区域
+	anchorCells       map[string]string                 // 按 sheet 指定的表格左上角单元格
+	placeholders      interface{}                       // 模板中占位符的值
+	continueOnError   bool                              // 数据行写入失败时是否跳过该行继续写入
+	skipNilRows       bool                              // 是否跳过为 nil 的数据
+	errorSheet        string                            // 记录跳过的数据行的 sheet
+	lenientTypes      bool                              // 是否将不支持的类型按 fmt.Sprintf("%v") 展示
+	beforeRowHook     BeforeRowHook                     // 每行数据写入前的回调
+	rowFilter         func(model SheetModel) bool       // 返回 false 的数据不写入
+	afterRowHook      AfterRowHook                      // 每行数据写入后的回调
+	logger            Logger                            // 输出警告的日志
+	warnings          map[string]bool                   // 已输出的警告, 写入时记录
+	tempFileThreshold int64                             // 使用流式写入的数据估算大小, 为-1时不使用
+	streaming         bool                              // 是否使用流式写入, 写入时按 tempFileThreshold 判断
+	subHeaders        map[string][]map[string]string    // 按 sheet 指定的表头下方的多行子表头
+	headerComments    map[string]string                 // 按表头指定的批注
+	rowNumberHeader   string                            // 序号列的表头, 为空时不插入序号列
+	collisionPolicy   SheetCollisionPolicy              // 不同类型的数据写入同一个 sheet 且表头不一致时的处理方式
+	imageFetcher      ImageFetcher                      // 下载 Image.URL 的函数
+	charts            []sheetChart                      // 按 sheet 指定的图表
+	sparklineRows     *int                              // 写入 sparklineSheet 的行数, 写入时记录
+	summarySheet      string                            // 列出所有数据 sheet 的 sheet
+	backLinkText      string                            // 每个 sheet 中链接到 summarySheet 的单元格的文本
+	sheetBackgrounds  map[string]string                 // 按 sheet 指定的背景图片的路径
+	checkboxes        bool                              // bool 类型的列是否展示为复选框
+	checkboxHeaders   []string                          // 展示为复选框的列的表头, 为空时为所有 bool 类型的列
+	columnValidations []columnValidation                // 按 sheet 和列指定的数据校验
+}
+
+// sheetLayout records the layout of a written sheet
+type sheetLayout struct {
+	columns       []column               // columns of the first model written to the sheet
+	modelType     reflect.Type           // type of the first model written to the sheet
+	blockType     reflect.Type           // type of the model of the current header block, see WithSheetCollisionPolicy
+	headers       []string               // headers of the current header block
+	blockRow      int                    // row index of the last header row of the current header block, see WithRowNumbers
+	firstRow      int                    // row number of the top left cell, it is 1 unless WithAnchorCell is set
+	firstCol      int                    // column number of the top left cell, it is 1 unless WithAnchorCell is set
+	titleRows     int                    // number of title rows set by WithSheetTitle, they are counted in headerRows
+	headerRows    int                    // number of header rows
+	rows          int                    // number of rows, including header rows
+	dataRows      int                    // number of written data rows, excluding header blocks, see WithSummarySheet
+	stream        *excelize.StreamWriter // stream writer of the sheet if WithTempFileThreshold is applied
+	colNames      []string               // names of the columns, such as "A", they are converted once per sheet
+	numberFormats []string               // number formats applied to the cells of the columns, see ExplainLayout
+}
+
+// row returns the excel row number of the row (start from 1) in layout
+func (l *sheetLayout) row(row int) int {
+	return l.firstRow - 1 + row
+}
+
+// col returns the excel column number of the column (start from 1) in layout
+func (l *sheetLayout) col(col int) int {
+	return l.firstCol - 1 + col
+}
+
+// cellName returns the name of the cell at col and row in layout
+func (l *sheetLayout) cellName(col, row int) (string, error) {
+	if col < 1 || col > len(l.colNames) || l.row(row) < 1 || l.row(row) > excelize.TotalRows {
+		return coordinatesToCellName(l.col(col), l.row(row)) // not in columns, or invalid
+	}
+	return l.colNames[col-1] + strconv.Itoa(l.row(row)), nil
 }
 
+// BytesFormat []byte 类型字段的展示格式, 也可以通过 excel_bytes tag 为单个字段指定, 如 `excel_bytes:"hex"`
+type BytesFormat string
+
+const (
+	BytesFormatString BytesFormat = "string" // UTF-8 字符串
+	BytesFormatHex    BytesFormat = "hex"    // 十六进制, 如 48656c6c6f
+	BytesFormatBase64 BytesFormat = "base64" // 标准 base64, 如 SGVsbG8=
+)
+
+// DurationFormat time.Duration 类型字段的展示格式
+type DurationFormat int
+
+const (
+	DurationFormatString    DurationFormat = iota // time.Duration.String(), 如 1h2m3s
+	DurationFormatClock                           // 时钟格式 h:mm:ss, 如 1:02:03
+	DurationFormatSeconds                         // 秒数, 如 3723, 3.5
+	DurationFormatHumanized                       // 省略为0的单位, 如 1d 2h 3m 4s
+)
+
+// MapFormat map 类型字段的展示格式
+type MapFormat int
+
+const (
+	MapFormatNone     MapFormat = iota // 不支持 map 类型, 返回 unsupported type 错误
+	MapFormatJSON                      // 紧凑 JSON, 如 {"k1":"v1","k2":"v2"}
+	MapFormatKeyValue                  // 键值对, 按 key 排序, 如 k1=v1; k2=v2
+)
+
 // WithTimeFormatLayout 时间类型的格式化版图
 func WithTimeFormatLayout(layout string) Option {
 	return func(options *options) {
@@ -220,12 +570,14 @@ func WithTimeFormatLayout(layout string) Option {
 	}
 }
 
+// WithFloatPrecision 小数保留多少位, 为-1时使用能准确表示该值的最少位数, 见 strconv.FormatFloat
 func WithFloatPrecision(precision int) Option {
 	return func(options *options) {
 		options.floatPrecision = precision
 	}
 }
 
+// WithFloatFmt 小数的格式, 如'f', 'e', 'g', 详细见 strconv.FormatFloat 的注释
 func WithFloatFmt(fmt byte) Option {
 	return func(options *options) {
 		options.floatFmt = fmt
@@ -239,6 +591,33 @@ func WithIfNullValue(value string) Option {
 	}
 }
 
+// WithSubHeaders 在 sheet 的表头下方追加多行子表头, 如单位, 说明, 每个 map 为一行, key 为表头, value 为该列的子表头,
+// 子表头与表头使用相同的样式, 冻结表头, 筛选和打印标题行等均包含子表头
+func WithSubHeaders(sheet string, rows ...map[string]string) Option {
+	return func(options *options) {
+		if options.subHeaders == nil {
+			options.subHeaders = make(map[string][]map[string]string)
+		}
+		options.subHeaders[sanitizeSheetName(sheet)] = rows
+	}
+}
+
+// WithHeaderComments 为表头添加批注, 说明列的含义和格式, 用于共享的模板, key 为表头, value 为批注的内容,
+// 也可以通过 excel_desc tag 为单个字段指定, 如 `excel_desc:"下单时间, 格式为 2006-01-02"`, 同时设置时 comments 优先
+func WithHeaderComments(comments map[string]string) Option {
+	return func(options *options) {
+		options.headerComments = comments
+	}
+}
+
+// WithRowNumbers 在每个 sheet 的第一列插入表头为 header 的序号列, 如 "序号", 序号从1开始,
+// 每个 sheet (包括 WithAutoSplitSheets 拆分的 sheet) 和 WithSheetCollisionPolicy 写入的每个表头块重新计数
+func WithRowNumbers(header string) Option {
+	return func(options *options) {
+		options.rowNumberHeader = header
+	}
+}
+
 // WithSheetHeaders 当没有数据时，默认也要展示表头
 func WithSheetHeaders(headers ...SheetModel) Option {
 	return func(options *options) {
@@ -268,120 +647,1302 @@ func WithHeadless() Option {
 	}
 }
 
-func appendRow(f *excelize.File, sheetModel SheetModel, line int, options *options) error {
-	sheetName := sheetModel.SheetName()
-	// find if sheetName exists
-	sheetIndex := f.GetSheetIndex(sheetName)
-	if sheetIndex == 0 {
-		f.NewSheet(sheetName) // create sheet
+// WithHeaderSeparator 嵌套结构体字段展开后, 父子表头之间的连接符, 默认为"."
+// 如 Address 字段的 City 字段表头为 "Address.City"
+func WithHeaderSeparator(separator string) Option {
+	return func(options *options) {
+		options.headerSeparator = separator
 	}
-	// check if sheetModel is pointer
-	if reflect.TypeOf(sheetModel).Kind() == reflect.Ptr {
-		if reflect.ValueOf(sheetModel).Elem().CanAddr() { // check if sheetModel is nil
-			// replace to sheetModel's reference value
-			// if type(sheetModel) is SheetModel, then *sheetModel is still SheetModel
-			sheetModel = reflect.Indirect(reflect.ValueOf(sheetModel)).Interface().(SheetModel)
-		} else {
-			return errors.New("nil reference row append is not allowed")
-		}
+}
+
+// WithDedupeHeaders 多个字段的表头相同时, 为后面的表头添加序号后缀, 如 "name (2)", 而不是返回错误
+func WithDedupeHeaders() Option {
+	return func(options *options) {
+		options.dedupeHeaders = true
 	}
+}
 
-	modelType := reflect.TypeOf(sheetModel)
-	line++                              // index start from 0 but excel start from 1
-	if line == 1 && !options.headless { // set header
-		for i := 0; i < modelType.NumField(); i++ {
-			field := modelType.Field(i)
-			header := field.Tag.Get("excel_header")
-			if header == "" { // if no excel_header tag, use field name as header
-				header = field.Name
-			}
-			cellName, err := coordinatesToCellName(i+1, 1)
-			if err != nil {
-				return err
-			}
-			f.SetCellValue(sheetName, cellName, header) // set header
-		}
-		line++ // set data first line
+// WithSliceDelimiter slice, array 类型字段的元素分隔符, 默认为", "
+// 也可以通过 excel_join tag 为单个字段指定, 如 `excel_join:";"`
+func WithSliceDelimiter(delimiter string) Option {
+	return func(options *options) {
+		options.sliceDelimiter = delimiter
 	}
-	for i := 0; i < modelType.NumField(); i++ {
-		field := modelType.Field(i)
-		cellName, err := coordinatesToCellName(i+1, line)
-		if err != nil {
-			return err
+}
+
+// WithMapFormat map 类型字段的展示格式, 见 MapFormat
+func WithMapFormat(format MapFormat) Option {
+	return func(options *options) {
+		options.mapFormat = format
+	}
+}
+
+// WithStringerFallback 非基础类型(struct, slice, map 等)实现了 fmt.Stringer 时, 使用 String() 的返回值展示,
+// 如 uuid.UUID, 枚举类型, 否则按内置规则展开或返回 unsupported type 错误
+func WithStringerFallback() Option {
+	return func(options *options) {
+		options.stringerFallback = true
+	}
+}
+
+// WithLenientTypes 不支持的类型(如 complex128, chan, 未设置 WithMapFormat 时的 map)按 fmt.Sprintf("%v") 展示,
+// 而不是返回 unsupported type 错误, 适用于调试时导出完整数据
+func WithLenientTypes() Option {
+	return func(options *options) {
+		options.lenientTypes = true
+	}
+}
+
+// WithDurationFormat time.Duration 类型字段的展示格式, 见 DurationFormat
+func WithDurationFormat(format DurationFormat) Option {
+	return func(options *options) {
+		options.durationFormat = format
+	}
+}
+
+// WithDecimalPlaces decimal 类型(实现了 StringFixed(int32) string 方法, 如 shopspring/decimal)保留的小数位数,
+// 以字符串形式展示避免精度丢失, 不设置时按其 String() 原样展示
+func WithDecimalPlaces(places int32) Option {
+	return func(options *options) {
+		options.decimalPlaces = &places
+	}
+}
+
+// WithBytesFormat []byte 类型字段的展示格式, 见 BytesFormat
+func WithBytesFormat(format BytesFormat) Option {
+	return func(options *options) {
+		options.bytesFormat = format
+	}
+}
+
+// WithRawJSONIndent json.RawMessage 类型字段按 indent 缩进格式化后展示, 默认原样展示
+func WithRawJSONIndent(indent string) Option {
+	return func(options *options) {
+		options.rawJSONIndent = indent
+	}
+}
+
+// WithRawJSONMaxLength json.RawMessage 类型字段最多展示 maxLength 个字符, 超出部分截断并以"..."结尾
+func WithRawJSONMaxLength(maxLength int) Option {
+	return func(options *options) {
+		options.rawJSONMaxLength = maxLength
+	}
+}
+
+// CellOverflowPolicy 单元格内容超过 excel 的上限32767个字符时的处理方式
+type CellOverflowPolicy int
+
+const (
+	CellOverflowTruncate CellOverflowPolicy = iota // 截断为32767个字符, 通过 WithLogger 输出警告
+	CellOverflowEllipsis                           // 截断并以"..."结尾, 使截断的内容可以被识别
+	CellOverflowError                              // 返回包含单元格位置的 *CellError
+	CellOverflowComment                            // 截断, 超出的部分写入该单元格的批注, 不支持流式写入
+)
+
+// WithCellOverflowPolicy 设置单元格内容超过32767个字符时的处理方式, 默认为 CellOverflowTruncate
+func WithCellOverflowPolicy(policy CellOverflowPolicy) Option {
+	return func(options *options) {
+		options.overflowPolicy = policy
+	}
+}
+
+// WithValueMapping 表头为 header 的列, 值在 mapping 中时展示为对应的内容, 如状态码展示为文字,
+// mapping 的 key 需要与字段值类型一致; 也可以通过 excel_map tag 为字段指定, 如 `excel_map:"1=active;2=disabled"`
+func WithValueMapping(header string, mapping map[interface{}]string) Option {
+	return func(options *options) {
+		if options.valueMappings == nil {
+			options.valueMappings = make(map[string]map[interface{}]string)
 		}
+		options.valueMappings[header] = mapping
+	}
+}
 
-		fieldValue := reflect.ValueOf(sheetModel).Field(i) // get field value
-		fieldKind := field.Type.Kind()                     // get field kind
-	unAddrTo:
-		switch fieldKind {
-		case reflect.Pointer: // if field is pointer, get its value
-			canAddr := fieldValue.Elem().CanAddr() // check if can get its value
-			if !canAddr {
-				f.SetCellValue(sheetName, cellName, options.ifNullValue) // null pointer
-			} else {
-				fieldValue = reflect.Indirect(fieldValue) // get value of pointer point to
-				fieldKind = fieldValue.Kind()             // get kind of pointer point to
-				goto unAddrTo                             // jump to unAddrTo, because now field is not pointer
-			}
-		case reflect.Struct, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-			reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Float32, reflect.Float64:
-			valueInterface := fieldValue.Interface() // get field value (type interface{})
-			switch value := valueInterface.(type) {  // type assertion
-			case int, int8, int16, int32, int64:
-				if options.integerAsString {
-					f.SetCellValue(sheetName, cellName, strconv.FormatInt(fieldValue.Int(), 10)) // set int cell value
-				} else {
-					f.SetCellValue(sheetName, cellName, value)
-				}
-			case uint, uint8, uint16, uint32, uint64:
-				if options.integerAsString {
-					f.SetCellValue(sheetName, cellName, strconv.FormatUint(fieldValue.Uint(), 10)) // set uint cell value
-				} else {
-					f.SetCellValue(sheetName, cellName, value)
-				}
-			case string:
-				f.SetCellValue(sheetName, cellName, value) // set string cell value
-			case bool: // convert bool to string using options
-				if options.trueValue != nil && value { // if trueValue is set and value is true
-					f.SetCellValue(sheetName, cellName, *options.trueValue)
-				} else if options.falseValue != nil && !value { // if falseValue is set and value is false
-					f.SetCellValue(sheetName, cellName, *options.falseValue)
-				} else { // using default
-					f.SetCellValue(sheetName, cellName, value)
-				}
-			case float32: // convert float32 to string using options
-				f.SetCellValue(sheetName,
-					cellName,
-					strconv.FormatFloat(
-						float64(value),
-						options.floatFmt,
-						options.floatPrecision,
-						32,
-					),
-				)
-			case float64: // convert float64 to string using options
-				f.SetCellValue(sheetName,
-					cellName,
-					strconv.FormatFloat(
-						value,
-						options.floatFmt,
-						options.floatPrecision,
-						64,
-					),
-				)
-			case time.Time: // convert time.Time to string using options
-				f.SetCellValue(sheetName, cellName, value.Format(options.timeFormatLayout))
-			default:
-				return fmt.Errorf("unsupported type %T", value)
-			}
-
-		case reflect.Map, reflect.Slice, reflect.Array, reflect.Chan, reflect.Func, reflect.Interface,
-			reflect.Invalid, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128, reflect.Uintptr:
-			return fmt.Errorf("unsupported type %s", fieldKind)
+// WithTimeAsNativeExcelDate time.Time 写为 excel 原生的日期时间单元格(序列数值), 而不是格式化后的字符串,
+// 以便在 excel 中排序, 筛选和计算, numberFormat 为 excel 的数字格式, 为空时为 "yyyy-mm-dd hh:mm:ss",
+// 设置后 WithTimeFormatLayout 仅对 slice, map 中的元素生效
+func WithTimeAsNativeExcelDate(numberFormat string) Option {
+	return func(options *options) {
+		if numberFormat == "" {
+			numberFormat = "yyyy-mm-dd hh:mm:ss"
 		}
+		options.timeAsNativeDate = true
+		options.timeNumberFormat = numberFormat
+	}
+}
+
+// WithFloatAsNumber float32, float64 写为 excel 原生的数字单元格, 而不是格式化后的字符串, 以便在 excel 中使用 SUM, AVERAGE 等公式,
+// numberFormat 为 excel 的数字格式, 如 "#,##0.00", 为空时按 WithFloatPrecision 的小数位数展示,
+// 设置后 WithFloatFmt 仅对 slice, map 中的元素生效
+func WithFloatAsNumber(numberFormat string) Option {
+	return func(options *options) {
+		options.floatAsNumber = true
+		options.floatNumberFormat = numberFormat
+	}
+}
+
+// WithTimeLocation time.Time 展示前转换到 location 时区, 如数据库中的 UTC 时间按报表查看者的时区展示,
+// 也可以通过 excel_tz tag 为单个字段指定, 如 `excel_tz:"Asia/Shanghai"`
+func WithTimeLocation(location *time.Location) Option {
+	return func(options *options) {
+		options.timeLocation = location
+	}
+}
+
+// WithZeroTimeValue time.Time 为零值(如 time.Time{})时展示 value, 而不是 0001-01-01 00:00:00
+func WithZeroTimeValue(value string) Option {
+	return func(options *options) {
+		options.zeroTimeValue = &value
+	}
+}
+
+// WithAutoFitColumns 按每列最长的内容(中日韩等全角字符按两个字符计算)自动调整列宽, 最大列宽见 WithMaxColumnWidth
+func WithAutoFitColumns() Option {
+	return func(options *options) {
+		options.autoFitColumns = true
+	}
+}
+
+// WithMaxColumnWidth 自动调整列宽时的最大列宽, 默认为60
+func WithMaxColumnWidth(width float64) Option {
+	return func(options *options) {
+		options.maxColumnWidth = width
+	}
+}
+
+// WithZebraStripes 每个 sheet 的数据行隔行填充背景色 fillColor, 如 "#F2F2F2", 从第二行数据开始填充
+func WithZebraStripes(fillColor string) Option {
+	return func(options *options) {
+		options.zebraFillColor = fillColor
+	}
+}
+
+// WithConditionalFormat 为 sheet 中表头为 columnHeader 的列的数据区域设置条件格式, 如负数标红,
+// style 为满足条件时的样式, 可以为 nil (如数据条, 色阶等不需要样式的规则), 不为 nil 时覆盖 rule.Format
+// example usage:
+//
+//	excelorm.WithConditionalFormat("accounts", "balance", excelize.ConditionalFormatOptions{
+//		Type:     "cell",
+//		Criteria: "<",
+//		Value:    "0",
+//	}, &excelize.Style{Font: &excelize.Font{Color: "#FF0000"}})
+func WithConditionalFormat(sheet, columnHeader string, rule excelize.ConditionalFormatOptions, style *excelize.Style) Option {
+	return func(options *options) {
+		options.conditionalRules = append(options.conditionalRules, conditionalRule{
+			sheet:  sanitizeSheetName(sheet),
+			header: columnHeader,
+			rule:   rule,
+			style:  style,
+		})
+	}
+}
+
+// defaultDataBarColor is the color of data bars set by WithDataBars
+const defaultDataBarColor = "#638EC6"
+
+// WithDataBars 为 sheet 中表头为 columnHeader 的列设置数据条, 按列中的最小值和最大值在单元格中显示长度不同的条形,
+// 数值列需要写入为数字, float 类型的列需要同时设置 WithFloatAsNumber
+func WithDataBars(sheet, columnHeader string) Option {
+	return WithConditionalFormat(sheet, columnHeader, excelize.ConditionalFormatOptions{
+		Type:     "data_bar",
+		Criteria: "=",
+		MinType:  "min",
+		MaxType:  "max",
+		BarColor: defaultDataBarColor,
+	}, nil)
+}
+
+// WithColorScale 为 sheet 中表头为 columnHeader 的列设置色阶(热力图), 最小值, 中间值(50%)和最大值的颜色分别为
+// minColor, midColor 和 maxColor, 如 "#F8696B", midColor 为空时为双色色阶; 数值列需要写入为数字, 同 WithDataBars
+func WithColorScale(sheet, columnHeader, minColor, midColor, maxColor string) Option {
+	rule := excelize.ConditionalFormatOptions{
+		Type:     "2_color_scale",
+		Criteria: "=",
+		MinType:  "min",
+		MaxType:  "max",
+		MinColor: minColor,
+		MaxColor: maxColor,
+	}
+	if midColor != "" {
+		rule.Type, rule.MidType, rule.MidValue, rule.MidColor = "3_color_scale", "percentile", "50", midColor
+	}
+	return WithConditionalFormat(sheet, columnHeader, rule, nil)
+}
+
+// WithFreezeHeader 冻结每个 sheet 的表头行, 滚动时表头保持可见, 对 WithFreezePanes 指定的 sheet 不生效
+func WithFreezeHeader() Option {
+	return func(options *options) {
+		options.freezeHeader = true
+	}
+}
+
+// WithFreezePanes 冻结 sheet 中单元格 cell 上方的行和左侧的列, 如 "B2" 冻结表头行和第一列
+func WithFreezePanes(sheet, cell string) Option {
+	return func(options *options) {
+		if options.freezePanes == nil {
+			options.freezePanes = make(map[string]string)
+		}
+		options.freezePanes[sanitizeSheetName(sheet)] = cell
+	}
+}
+
+// WithAutoFilter 为每个 sheet 的表头添加筛选下拉框, 筛选范围为全部数据行, WithHeadless 时不生效
+func WithAutoFilter() Option {
+	return func(options *options) {
+		options.autoFilter = true
+	}
+}
+
+// CellStyleFunc 返回数据单元格的样式, row, col 为单元格的行号和列号(从1开始), value 为字段的值(嵌套结构体指针为 nil 时为 nil),
+// 返回 nil 时使用默认样式
+type CellStyleFunc func(sheet string, row, col int, value interface{}) *excelize.Style
+
+// WithCellStyleFunc 按数据为单元格设置样式, 如高亮逾期的日期, 按状态设置颜色,
+// 返回相同的 *excelize.Style 的单元格共用一个样式, 因此应尽量复用返回值而不是每次新建;
+// 样式未设置数字格式或填充时, 保留 WithTimeAsNativeExcelDate, WithZebraStripes 等设置的数字格式和填充
+func WithCellStyleFunc(fn CellStyleFunc) Option {
+	return func(options *options) {
+		options.cellStyleFunc = fn
+	}
+}
+
+// RowStyleFunc 返回数据行的样式, row 为行号(从1开始), 返回 nil 时使用 RowStyler 或默认样式
+type RowStyleFunc func(sheet string, row int, model SheetModel) *excelize.Style
+
+// WithRowStyleFunc 按数据为整行单元格设置样式, 如错误的数据标红, 优先级高于 RowStyler, 低于 WithCellStyleFunc,
+// 与 WithCellStyleFunc 一样应尽量复用返回的 *excelize.Style
+func WithRowStyleFunc(fn RowStyleFunc) Option {
+	return func(options *options) {
+		options.rowStyleFunc = fn
+	}
+}
+
+// WithTableBorders 为每个 sheet 的表头, 数据行和汇总行的单元格添加边框, style 为 excelize 的边框线条样式,
+// 如 1 为细实线, 2 为中等实线, 为 0 时为细实线, color 为边框颜色, 如 "#000000", 为空时为自动颜色
+func WithTableBorders(style int, color string) Option {
+	return func(options *options) {
+		if style == 0 {
+			style = 1
+		}
+		options.tableBorders = true
+		options.borderStyle = style
+		options.borderColor = color
+	}
+}
+
+// WithDefaultFont 工作簿的默认字体 name 和字号 size, 如 "宋体", 11, name 为空时不改变字体, size 为0时不改变字号,
+// 单元格样式(如 WithCellStyleFunc 返回的样式)未指定字体或字号时也使用该设置
+func WithDefaultFont(name string, size float64) Option {
+	return func(options *options) {
+		options.fontName = name
+		options.fontSize = size
+	}
+}
+
+// WithRowHeight 数据行(包括 WithSummaryRow 的汇总行)的行高, 如多行文本需要更高的行高才能完整展示
+func WithRowHeight(height float64) Option {
+	return func(options *options) {
+		options.rowHeight = height
+	}
+}
+
+// WithHeaderRowHeight 表头行的行高
+func WithHeaderRowHeight(height float64) Option {
+	return func(options *options) {
+		options.headerRowHeight = height
+	}
+}
+
+// RowGroupFunc 返回数据行的分级显示级别, row 为行号(从1开始), 返回 0 时使用 RowGrouper 的级别
+type RowGroupFunc func(sheet string, row int, model SheetModel) int
+
+// WithRowGroupFunc 按数据设置行的分级显示级别, 使行可以在 excel 中折叠展开, 优先级高于 RowGrouper
+func WithRowGroupFunc(fn RowGroupFunc) Option {
+	return func(options *options) {
+		options.rowGroupFunc = fn
+	}
+}
+
+// appendRow appends sheetModel as a row of sheetName, the sheet is created on its first row,
+// created sheets are looked up in options.sheetLayouts rather than the workbook
+func appendRow(f *excelize.File, sheetName string, sheetModel SheetModel, options *options) error {
+	// check if sheetModel is pointer
+	if reflect.TypeOf(sheetModel).Kind() == reflect.Ptr {
+		if reflect.ValueOf(sheetModel).Elem().CanAddr() { // check if sheetModel is nil
+			// replace to sheetModel's reference value
+			// if type(sheetModel) is SheetModel, then *sheetModel is still SheetModel
+			sheetModel = reflect.Indirect(reflect.ValueOf(sheetModel)).Interface().(SheetModel)
+		} else {
+			return ErrNilRow
+		}
+	}
+
+	modelValue := valueOfModel(sheetModel)
+	columns, err := parseColumns(modelValue.Type(), options)
+	if err != nil {
+		return err
+	}
+	baseName, parts := sheetName, options.sheetParts[sheetName]
+	if parts > 1 {
+		sheetName = splitSheetName(baseName, parts) // continue writing the last split sheet
+	}
+	layout, ok := options.sheetLayouts[sheetName]
+	if ok && layout.row(layout.rows) >= options.maxRows {
+		if !options.autoSplitSheets {
+			return fmt.Errorf("sheet %s exceeds the limit of %d rows", sheetName, options.maxRows)
+		}
+		if parts == 0 {
+			parts = 1
+		}
+		parts++
+		options.sheetParts[baseName] = parts
+		sheetName, ok = splitSheetName(baseName, parts), false
+	}
+	if !ok { // create sheet and set header
+		if layout, err = newSheetLayout(f, sheetName, modelValue.Type(), columns, !options.headless, options); err != nil {
+			return err
+		}
+	} else if err = checkSheetCollision(f, sheetName, layout, modelValue.Type(), columns, options); err != nil {
+		return err
+	}
+	line := layout.rows + 1             // row index in layout, start from 1
+	dataRow := line - layout.headerRows // index of data row, start from 1
+	rowStyle := getRowStyle(sheetName, sheetModel, modelValue, layout.row(line), options)
+	level := getGroupLevel(sheetName, sheetModel, modelValue, layout.row(line), options)
+	if level < 0 || level > 7 {
+		return fmt.Errorf("group level %d out of range [0, 7]", level)
+	}
+	values := make([]interface{}, len(columns))
+	styles := make([]cellStyle, len(columns))
+	for i, column := range columns {
+		var value, rawValue interface{} = options.ifNullValue, nil // nil pointer to nested struct
+		if column.rowNumber {
+			value = line - layout.blockRow
+			rawValue = value
+		} else if fieldValue, ok := fieldByIndex(modelValue, column.index); ok {
+			if column.primitive {
+				value = formatPrimitive(fieldValue, options) // fast path of formatValue
+			} else if value, err = formatValue(fieldValue, column, options); err != nil { // get field value
+				return &CellError{Sheet: sheetName, Row: layout.row(line), Column: column.header, Field: column.field.Name, Err: err}
+			}
+			rawValue = value
+			if options.cellStyleFunc != nil && fieldValue.CanInterface() {
+				rawValue = fieldValue.Interface()
+			}
+		}
+		values[i] = value
+		var style cellStyle
+		switch value.(type) {
+		case time.Time: // only returned by formatValue when options.timeAsNativeDate is set
+			style.numberFormat = options.timeNumberFormat
+		case float32, float64: // only returned by formatValue when options.floatAsNumber is set
+			style.numberFormat = floatNumberFormat(options)
+		}
+		if options.zebraFillColor != "" && dataRow%2 == 0 {
+			style.fillColor = options.zebraFillColor
+		}
+		if options.cellStyleFunc != nil {
+			style.custom = options.cellStyleFunc(sheetName, layout.row(line), layout.col(i+1), rawValue)
+		}
+		if style.custom == nil {
+			style.custom = rowStyle
+		}
+		style.border = options.tableBorders
+		styles[i] = style
+		if style.numberFormat != "" && i < len(layout.numberFormats) && layout.numberFormats[i] == "" {
+			layout.numberFormats[i] = style.numberFormat // recorded for ExplainLayout
+		}
+	}
+	var comments []excelize.Comment
+	for i, value := range values {
+		text, ok := value.(string)
+		if !ok || len(text) <= excelize.TotalCellChars || utf8.RuneCountInString(text) <= excelize.TotalCellChars {
+			continue
+		}
+		cellName, err := layout.cellName(i+1, line)
+		if err != nil {
+			return err
+		}
+		runes := []rune(text)
+		switch options.overflowPolicy {
+		case CellOverflowEllipsis:
+			values[i] = string(runes[:excelize.TotalCellChars-3]) + "..."
+		case CellOverflowError:
+			err = fmt.Errorf("value has %d characters, exceeds the limit %d", len(runes), excelize.TotalCellChars)
+			return &CellError{Sheet: sheetName, Row: layout.row(line), Column: columns[i].header, Field: columns[i].field.Name, Err: err}
+		case CellOverflowComment:
+			values[i] = string(runes[:excelize.TotalCellChars])
+			overflow := string(runes[excelize.TotalCellChars:])
+			if len(overflow) > excelize.TotalCellChars { // comments are limited to TotalCellChars bytes
+				cut := excelize.TotalCellChars
+				for !utf8.RuneStart(overflow[cut]) {
+					cut--
+				}
+				overflow = overflow[:cut]
+			}
+			comments = append(comments, excelize.Comment{Cell: cellName, Text: overflow})
+		default:
+			options.warn("value of cell %s in sheet %s is truncated to %d characters", cellName, sheetName, excelize.TotalCellChars)
+		}
+	}
+	rowKey, duplicate, err := isDuplicateRow(baseName, layout, line, columns, values, options)
+	if err != nil || duplicate {
+		return err
+	}
+	pictures, err := rowPictures(sheetName, layout, line, columns, values, options)
+	if err != nil {
+		return err
+	}
+	sparklines, err := rowSparklines(sheetName, layout, line, columns, values, options)
+	if err != nil {
+		return err
+	}
+	checkboxes, err := rowCheckboxes(layout, line, modelValue, columns, values, styles, options)
+	if err != nil {
+		return err
+	}
+	layout.rows = line // the row is skipped if any of its values fails, see WithContinueOnError
+	rowOpts := excelize.RowOpts{Height: options.rowHeight, OutlineLevel: level}
+	if err = writeRow(f, sheetName, layout, line, values, styles, rowOpts, options); err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if err = f.AddComment(sheetName, comment); err != nil {
+			return err
+		}
+	}
+	for _, picture := range pictures {
+		if err = f.AddPictureFromBytes(sheetName, picture.cell, picture.picture); err != nil {
+			return err
+		}
+	}
+	for _, checkbox := range checkboxes {
+		if err = f.AddFormControl(sheetName, checkbox); err != nil {
+			return err
+		}
+	}
+	if err = addSparklines(f, sheetName, sparklines, options); err != nil {
+		return err
+	}
+	recordRowKey(baseName, rowKey, options)
+	layout.dataRows++
+	return nil
+}
+
+// rowValuer is implemented by SheetModels whose columns are the fields of another struct, such as structRow
+type rowValuer interface {
+	rowValue() reflect.Value
+}
+
+// structRow is a row of sheet, its columns are the fields of value
+type structRow struct {
+	sheet string
+	value reflect.Value
+}
+
+func (r structRow) SheetName() string {
+	return r.sheet
+}
+
+func (r structRow) rowValue() reflect.Value {
+	return r.value
+}
+
+// StructRow 将结构体 value 的字段作为 sheet 的一行, 用于无法定义 SheetName 方法的结构体, 如 reflect.StructOf 按配置创建的类型,
+// value 为指针时写入其指向的结构体, 为 nil 指针时返回 nil, 字段的 tag 同 SheetModel, 值不是结构体时写入返回 ErrNotStruct
+// example usage:
+//
+//	rowType := reflect.StructOf([]reflect.StructField{{Name: "Name", Type: reflect.TypeOf(""), Tag: `excel_header:"name"`}})
+//	row := reflect.New(rowType).Elem()
+//	row.Field(0).SetString("foo")
+//	excelorm.WriteExcelSaveAs("users.xlsx", []excelorm.SheetModel{excelorm.StructRow("users", row.Interface())})
+func StructRow(sheet string, value interface{}) SheetModel {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return structRow{sheet: sheet, value: v}
+}
+
+// valueOfModel returns the struct value whose fields are written as the columns of sheetModel
+func valueOfModel(sheetModel SheetModel) reflect.Value {
+	if valuer, ok := sheetModel.(rowValuer); ok {
+		return valuer.rowValue()
+	}
+	return reflect.ValueOf(sheetModel)
+}
+
+// newSheetLayout creates sheet named sheetName, writes its title set by WithSheetTitle and header if withHeader is true,
+// then records its layout
+func newSheetLayout(f *excelize.File, sheetName string, modelType reflect.Type, columns []column, withHeader bool,
+	options *options) (*sheetLayout, error) {
+	if err := newSheet(f, sheetName); err != nil {
+		return nil, err
+	}
+	layout := &sheetLayout{columns: columns, modelType: modelType, firstRow: 1, firstCol: 1}
+	layout.blockType, layout.headers = modelType, columnHeaders(columns)
+	if anchor, ok := options.anchorCells[sheetName]; ok {
+		var err error
+		if layout.firstCol, layout.firstRow, err = excelize.CellNameToCoordinates(anchor); err != nil {
+			return nil, err
+		}
+	}
+	layout.colNames = make([]string, len(columns))
+	layout.numberFormats = make([]string, len(columns))
+	for i := range columns {
+		colName, err := columnNumberToName(layout.col(i + 1))
+		if err != nil {
+			return nil, err
+		}
+		layout.colNames[i] = colName
+	}
+	if options.streaming {
+		if err := newStreamWriter(f, sheetName, layout, withHeader, options); err != nil {
+			return nil, err
+		}
+	}
+	if title, ok := options.sheetTitles[sheetName]; ok {
+		if err := writeTitle(f, sheetName, layout, title, options); err != nil {
+			return nil, err
+		}
+		layout.titleRows = 1
+		layout.headerRows = 1
+	}
+	if withHeader {
+		if err := writeHeader(f, sheetName, layout, layout.headerRows+1, layout.headers, options); err != nil {
+			return nil, err
+		}
+		if err := addHeaderComments(f, sheetName, layout, layout.headerRows+1, columns, options); err != nil {
+			return nil, err
+		}
+		layout.headerRows++
+		for _, labels := range options.subHeaders[sheetName] {
+			for header := range labels {
+				if layout.columnNumber(header) == 0 {
+					return nil, fmt.Errorf("column %s not found in sheet %s", header, sheetName)
+				}
+			}
+			headers := make([]string, len(columns))
+			for i, column := range columns {
+				headers[i] = labels[column.header]
+			}
+			if err := writeHeader(f, sheetName, layout, layout.headerRows+1, headers, options); err != nil {
+				return nil, err
+			}
+			layout.headerRows++
+		}
+	}
+	layout.rows, layout.blockRow = layout.headerRows, layout.headerRows
+	options.sheetLayouts[sheetName] = layout
+	return layout, nil
+}
+
+// writeHeader writes headers of columns of layout to row of sheet
+func writeHeader(f *excelize.File, sheetName string, layout *sheetLayout, row int, headers []string, options *options) error {
+	values := make([]interface{}, len(headers))
+	styles := make([]cellStyle, len(headers))
+	for i, header := range headers {
+		values[i] = header
+		styles[i] = cellStyle{border: options.tableBorders, custom: options.headerStyle}
+	}
+	link, ok := backLink(sheetName, layout, row, options)
+	if ok && layout.stream != nil { // the whole row is written at once
+		values = append(values, nil, link)
+		styles = append(styles, cellStyle{}, cellStyle{custom: hyperlinkStyle})
+	}
+	if err := writeRow(f, sheetName, layout, row, values, styles, excelize.RowOpts{Height: options.headerRowHeight}, options); err != nil {
+		return err
+	}
+	if !ok || layout.stream != nil {
+		return nil
+	}
+	cellName, err := layout.cellName(len(headers)+2, row)
+	if err != nil {
+		return err
+	}
+	if err = f.SetCellValue(sheetName, cellName, link.Value); err != nil {
+		return err
+	}
+	if err = f.SetCellFormula(sheetName, cellName, link.Formula); err != nil {
+		return err
+	}
+	return setCellStyle(f, sheetName, cellName, cellStyle{custom: hyperlinkStyle}, options)
+}
+
+// addHeaderComments adds the comments set by WithHeaderComments or excel_desc tags to the header cells of columns at row
+func addHeaderComments(f *excelize.File, sheetName string, layout *sheetLayout, row int, columns []column, options *options) error {
+	for i, column := range columns {
+		text, ok := options.headerComments[column.header]
+		if !ok {
+			text = column.field.Tag.Get("excel_desc")
+		}
+		if text == "" {
+			continue
+		}
+		cellName, err := layout.cellName(i+1, row)
+		if err != nil {
+			return err
+		}
+		if err = f.AddComment(sheetName, excelize.Comment{Cell: cellName, Text: text}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnHeaders returns the headers of columns
+func columnHeaders(columns []column) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+	return headers
+}
+
+// getRowStyle returns the style of row from options.rowStyleFunc or RowStyler implemented by sheetModel
+func getRowStyle(sheetName string, sheetModel SheetModel, modelValue reflect.Value, row int, options *options) *excelize.Style {
+	if options.rowStyleFunc != nil {
+		if style := options.rowStyleFunc(sheetName, row, sheetModel); style != nil {
+			return style
+		}
+	}
+	if styler, ok := valueAs(modelValue, rowStylerType); ok {
+		return styler.(RowStyler).RowStyle()
+	}
+	return nil
+}
+
+// getGroupLevel returns the outline level of row from options.rowGroupFunc or RowGrouper implemented by sheetModel
+func getGroupLevel(sheetName string, sheetModel SheetModel, modelValue reflect.Value, row int, options *options) int {
+	if options.rowGroupFunc != nil {
+		if level := options.rowGroupFunc(sheetName, row, sheetModel); level != 0 {
+			return level
+		}
+	}
+	if grouper, ok := valueAs(modelValue, rowGrouperType); ok {
+		return grouper.(RowGrouper).GroupLevel()
+	}
+	return 0
+}
+
+// column describes a single excel column, it may come from a nested struct field
+type column struct {
+	header    string              // header text, nested headers are joined with options.headerSeparator
+	index     []int               // index sequence of the field, see reflect.Value.FieldByIndex
+	field     reflect.StructField // the field itself, used to look up other tags
+	labels    map[string]string   // value labels parsed from excel_map tag
+	location  *time.Location      // time location parsed from excel_tz tag
+	primitive bool                // whether the field is written by formatPrimitive, see isPrimitiveColumn
+	rowNumber bool                // whether it is the sequence column inserted by WithRowNumbers
+}
+
+// rowNumberField is the struct field of the column inserted by WithRowNumbers, which is not a field of the model
+var rowNumberField = reflect.StructField{Name: "RowNumber", Type: reflect.TypeOf(0)}
+
+// parseColumns resolves the columns of modelType in field order,
+// nested struct fields (or pointers to them) are flattened into their own columns,
+// the result is cached in options so that rows of the same type are parsed only once
+func parseColumns(modelType reflect.Type, options *options) ([]column, error) {
+	if columns, ok := options.columnPlans[modelType]; ok {
+		return columns, nil
+	}
+	var columns []column
+	if options.rowNumberHeader != "" {
+		columns = append(columns, column{header: options.rowNumberHeader, field: rowNumberField, rowNumber: true})
+	}
+	columns, err := appendColumns(columns, modelType, "", nil, []reflect.Type{modelType}, options)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkDuplicateHeaders(modelType, columns, options); err != nil {
+		return nil, err
+	}
+	if options.columnPlans == nil {
+		options.columnPlans = make(map[reflect.Type][]column)
+	}
+	options.columnPlans[modelType] = columns
+	return columns, nil
+}
+
+// appendColumns appends columns of modelType to columns, parents are the struct types being
+// flattened, which stops the recursion of self-referential types such as `type Node struct { Parent *Node }`
+func appendColumns(columns []column, modelType reflect.Type, prefix string, index []int, parents []reflect.Type,
+	options *options) ([]column, error) {
+	var err error
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag := field.Tag.Get("excel_header")
+		if tag == "-" {
+			continue // skip this field if header is "-"
+		}
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+		fieldType := indirectType(field.Type)
+		nested := isNestedStruct(fieldType, options) && !containsType(parents, fieldType)
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct && nested) {
+			// skip unexported fields like encoding/json does, their values can not be read,
+			// but the exported fields of an embedded struct of unexported type are promoted
+			continue
+		}
+		if field.Anonymous && tag == "" && nested {
+			// promote fields of untagged embedded struct inline, like encoding/json does
+			columns, err = appendColumns(columns, fieldType, prefix, fieldIndex, append(parents, fieldType), options)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		header := tag
+		if header == "" { // if no excel_header tag, use field name as header
+			header = field.Name
+		}
+		if prefix != "" {
+			header = prefix + options.headerSeparator + header
+		}
+		if nested { // flatten nested struct, use its header as prefix
+			columns, err = appendColumns(columns, fieldType, header, fieldIndex, append(parents, fieldType), options)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var location *time.Location
+		if tz := field.Tag.Get("excel_tz"); tz != "" {
+			location, err = time.LoadLocation(tz)
+			if err != nil {
+				return nil, err
+			}
+		}
+		col := column{
+			header:   header,
+			index:    fieldIndex,
+			field:    field,
+			labels:   parseValueLabels(field.Tag.Get("excel_map")),
+			location: location,
+		}
+		col.primitive = isPrimitiveColumn(col, options)
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// checkDuplicateHeaders returns an error if two columns of modelType have the same header,
+// or renames the latter ones with suffixes such as "name (2)" if WithDedupeHeaders is set
+func checkDuplicateHeaders(modelType reflect.Type, columns []column, options *options) error {
+	fields := make(map[string]string, len(columns)) // field name of each header
+	for i, column := range columns {
+		field, ok := fields[column.header]
+		if !ok {
+			fields[column.header] = column.field.Name
+			continue
+		}
+		if !options.dedupeHeaders {
+			return fmt.Errorf("fields %s and %s of %s have the same header %s", field, column.field.Name, modelType, column.header)
+		}
+		header := column.header
+		for n := 2; ok; n++ {
+			header = fmt.Sprintf("%s (%d)", column.header, n)
+			_, ok = fields[header]
+		}
+		columns[i].header = header
+		fields[header] = column.field.Name
+	}
+	return nil
+}
+
+// indirectType returns the type t points to, no matter how many levels of pointer
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but dereferences pointers of any level on the path,
+// it returns false if a nil pointer is met
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isNestedStruct reports whether t is a struct which should be flattened into columns
+// rather than written as a single cell
+func isNestedStruct(t reflect.Type, options *options) bool {
+	if t.Kind() != reflect.Struct || isBuiltinType(t) {
+		return false
+	}
+	if _, ok := lookupTypeConverter(t); ok {
+		return false
+	}
+	if options.stringerFallback && implements(t, stringerType) {
+		return false
+	}
+	for _, interfaceType := range []reflect.Type{cellMarshalerType, decimalType, valuerType, textMarshalerType} {
+		if implements(t, interfaceType) {
+			return false
+		}
+	}
+	return true
+}
+
+// implements reports whether t or its pointer implements interfaceType
+func implements(t reflect.Type, interfaceType reflect.Type) bool {
+	return t.Implements(interfaceType) || reflect.PointerTo(t).Implements(interfaceType)
+}
+
+// isPrimitiveColumn reports whether column is an exported field of type string, bool, integer or float without
+// value labels and type converters, so that it can be written by formatPrimitive rather than formatValue
+func isPrimitiveColumn(column column, options *options) bool {
+	t := column.field.Type
+	if !column.field.IsExported() || t.PkgPath() != "" || column.labels != nil {
+		return false
+	}
+	if _, ok := options.valueMappings[column.header]; ok {
+		return false
+	}
+	if _, ok := lookupTypeConverter(t); ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formatPrimitive returns the cell value of fieldValue of a primitive column like formatValue,
+// without looking up the interfaces implemented by its type
+func formatPrimitive(fieldValue reflect.Value, options *options) interface{} {
+	switch fieldValue.Kind() {
+	case reflect.String:
+		return fieldValue.String()
+	case reflect.Bool:
+		value := fieldValue.Bool()
+		if options.trueValue != nil && value {
+			return *options.trueValue
+		} else if options.falseValue != nil && !value {
+			return *options.falseValue
+		}
+		return value
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if options.integerAsString {
+			return strconv.FormatInt(fieldValue.Int(), 10)
+		}
+		return fieldValue.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if options.integerAsString {
+			return strconv.FormatUint(fieldValue.Uint(), 10)
+		}
+		return fieldValue.Uint()
+	case reflect.Float32:
+		if options.floatAsNumber {
+			return float32(fieldValue.Float()) // excel number cell, styled by appendRow
+		}
+		return strconv.FormatFloat(fieldValue.Float(), options.floatFmt, options.floatPrecision, 32)
+	default: // reflect.Float64
+		if options.floatAsNumber {
+			return fieldValue.Float()
+		}
+		return strconv.FormatFloat(fieldValue.Float(), options.floatFmt, options.floatPrecision, 64)
+	}
+}
+
+// isBuiltinType reports whether values of t are rendered by the built-in type switch,
+// named types such as enums are not
+func isBuiltinType(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(time.Duration(0)),
+		reflect.TypeOf(big.Int{}), reflect.TypeOf(big.Float{}), reflect.TypeOf(big.Rat{}), imageType, sparklineType:
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64:
+		return t.PkgPath() == ""
+	}
+	return false
+}
+
+// valueAs returns v as interface{} if v or its pointer implements interfaceType
+func valueAs(v reflect.Value, interfaceType reflect.Type) (interface{}, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if v.Type().Implements(interfaceType) {
+		return v.Interface(), true
+	}
+	if v.Kind() != reflect.Pointer && reflect.PointerTo(v.Type()).Implements(interfaceType) {
+		if v.CanAddr() {
+			return v.Addr().Interface(), true
+		}
+		ptr := reflect.New(v.Type()) // v is not addressable, call method on its copy
+		ptr.Elem().Set(v)
+		return ptr.Interface(), true
+	}
+	return nil, false
+}
+
+// formatValue converts field value to the value written to cell using options
+func formatValue(fieldValue reflect.Value, column column, options *options) (interface{}, error) {
+	fieldKind := fieldValue.Kind() // get field kind
+unAddrTo:
+	if fieldKind == reflect.Interface { // dispatch on the dynamic value, such as interface{} field
+		if fieldValue.IsNil() {
+			return options.ifNullValue, nil
+		}
+		fieldValue = fieldValue.Elem()
+		fieldKind = fieldValue.Kind()
+	}
+	if fieldKind != reflect.Pointer {
+		if label, ok := mapValue(fieldValue, column, options); ok { // enum label
+			return label, nil
+		}
+	}
+	if fieldKind != reflect.Pointer || !fieldValue.IsNil() {
+		if converter, ok := lookupTypeConverter(fieldValue.Type()); ok && fieldValue.CanInterface() { // registered type
+			value, err := converter(fieldValue.Interface(), converterOptions(column, options))
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				return options.ifNullValue, nil
+			}
+			return value, nil
+		}
+		if marshaler, ok := valueAs(fieldValue, cellMarshalerType); ok { // custom type controls its own rendering
+			value, err := marshaler.(CellMarshaler).MarshalExcelCell()
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				return options.ifNullValue, nil
+			}
+			return value, nil
+		}
+		if !isBuiltinType(fieldValue.Type()) {
+			if decimal, ok := valueAs(fieldValue, decimalType); ok && options.decimalPlaces != nil { // such as decimal.Decimal
+				return decimal.(decimalLike).StringFixed(*options.decimalPlaces), nil
+			}
+			if valuer, ok := valueAs(fieldValue, valuerType); ok { // such as sql.NullString, sql.NullTime
+				value, err := valuer.(driver.Valuer).Value()
+				if err != nil {
+					return nil, err
+				}
+				if value == nil { // not valid
+					return options.ifNullValue, nil
+				}
+				return formatValue(reflect.ValueOf(value), column, options)
+			}
+			if textMarshaler, ok := valueAs(fieldValue, textMarshalerType); ok { // such as net.IP, big.Int
+				text, err := textMarshaler.(encoding.TextMarshaler).MarshalText()
+				if err != nil {
+					return nil, err
+				}
+				return string(text), nil
+			}
+			if stringer, ok := valueAs(fieldValue, stringerType); ok && options.stringerFallback { // such as uuid.UUID, enums
+				return stringer.(fmt.Stringer).String(), nil
+			}
+		}
+	}
+	switch fieldKind {
+	case reflect.Pointer: // if field is pointer, get its value
+		canAddr := fieldValue.Elem().CanAddr() // check if can get its value
+		if !canAddr {
+			return options.ifNullValue, nil // null pointer
+		}
+		fieldValue = reflect.Indirect(fieldValue) // get value of pointer point to
+		fieldKind = fieldValue.Kind()             // get kind of pointer point to
+		goto unAddrTo                             // jump to unAddrTo, because now field is not pointer
+	case reflect.Struct, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64:
+		valueInterface := fieldValue.Interface() // get field value (type interface{})
+		switch value := valueInterface.(type) {  // type assertion
+		case time.Duration: // convert time.Duration to string using options
+			return formatDuration(value, options), nil
+		case int, int8, int16, int32, int64:
+			if options.integerAsString {
+				return strconv.FormatInt(fieldValue.Int(), 10), nil // int cell value
+			}
+			return value, nil
+		case uint, uint8, uint16, uint32, uint64:
+			if options.integerAsString {
+				return strconv.FormatUint(fieldValue.Uint(), 10), nil // uint cell value
+			}
+			return value, nil
+		case string:
+			return value, nil // string cell value
+		case bool: // convert bool to string using options
+			if options.trueValue != nil && value { // if trueValue is set and value is true
+				return *options.trueValue, nil
+			} else if options.falseValue != nil && !value { // if falseValue is set and value is false
+				return *options.falseValue, nil
+			}
+			return value, nil // using default
+		case float32: // convert float32 to string using options
+			if options.floatAsNumber {
+				return value, nil // excel number cell, styled by appendRow
+			}
+			return strconv.FormatFloat(float64(value), options.floatFmt, options.floatPrecision, 32), nil
+		case float64: // convert float64 to string using options
+			if options.floatAsNumber {
+				return value, nil // excel number cell, styled by appendRow
+			}
+			return strconv.FormatFloat(value, options.floatFmt, options.floatPrecision, 64), nil
+		case time.Time: // convert time.Time to string using options
+			if value.IsZero() && options.zeroTimeValue != nil {
+				return *options.zeroTimeValue, nil
+			}
+			if column.location != nil {
+				value = value.In(column.location)
+			} else if options.timeLocation != nil {
+				value = value.In(options.timeLocation)
+			}
+			if options.timeAsNativeDate {
+				return value, nil // excel datetime cell, styled by appendRow
+			}
+			return value.Format(options.timeFormatLayout), nil
+		case big.Int: // always string, avoid precision loss and scientific notation
+			return value.String(), nil
+		case big.Float: // convert big.Float to string using float options
+			return value.Text(options.floatFmt, options.floatPrecision), nil
+		case big.Rat: // convert big.Rat to string using float precision
+			return value.FloatString(options.floatPrecision), nil
+		case Image: // anchored to the cell by appendRow
+			if value.isEmpty() {
+				return options.ifNullValue, nil
+			}
+			return value, nil
+		case Sparkline: // added to the cell by appendRow
+			if len(value.Values) == 0 {
+				return options.ifNullValue, nil
+			}
+			return value, nil
+		default:
+			if options.lenientTypes {
+				return fmt.Sprintf("%v", value), nil
+			}
+			return nil, fmt.Errorf("%w %T", ErrUnsupportedType, value)
+		}
+	case reflect.Slice, reflect.Array: // join elements as string
+		if fieldKind == reflect.Slice && fieldValue.IsNil() {
+			return options.ifNullValue, nil // nil slice
+		}
+		if fieldValue.Type() == reflect.TypeOf(json.RawMessage{}) { // raw JSON text
+			return formatRawJSON(fieldValue.Bytes(), options)
+		}
+		if fieldKind == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.Uint8 { // []byte
+			return formatBytes(fieldValue.Bytes(), column, options)
+		}
+		delimiter, ok := column.field.Tag.Lookup("excel_join")
+		if !ok {
+			delimiter = options.sliceDelimiter
+		}
+		elements := make([]string, fieldValue.Len())
+		for i := range elements {
+			element, err := formatText(fieldValue.Index(i), column, options)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return strings.Join(elements, delimiter), nil
+	case reflect.Map:
+		if options.mapFormat == MapFormatNone {
+			break
+		}
+		if fieldValue.IsNil() {
+			return options.ifNullValue, nil // nil map
+		}
+		if options.mapFormat == MapFormatJSON {
+			b, err := json.Marshal(fieldValue.Interface())
+			if err != nil {
+				return nil, err
+			}
+			return string(b), nil
+		}
+		pairs := make([]string, 0, fieldValue.Len())
+		iter := fieldValue.MapRange()
+		for iter.Next() {
+			value, err := formatText(iter.Value(), column, options)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, fmt.Sprintf("%v=%v", iter.Key().Interface(), value))
+		}
+		sort.Strings(pairs) // map is unordered, sort pairs to keep output stable
+		return strings.Join(pairs, "; "), nil
+	}
+	if options.lenientTypes && fieldValue.CanInterface() {
+		return fmt.Sprintf("%v", fieldValue.Interface()), nil
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnsupportedType, fieldKind)
+}
+
+// formatText is like formatValue, but always converts the value to string,
+// it's used to format elements of slice and map
+func formatText(fieldValue reflect.Value, column column, options *options) (string, error) {
+	value, err := formatValue(fieldValue, column, options)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(options.timeFormatLayout), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), options.floatFmt, options.floatPrecision, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, options.floatFmt, options.floatPrecision, 64), nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// parseValueLabels parses excel_map tag like `excel_map:"1=active;2=disabled"`
+func parseValueLabels(tag string) map[string]string {
+	if tag == "" {
+		return nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(tag, ";") {
+		key, label, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(label)
+	}
+	return labels
+}
+
+// mapValue looks up the label of value from WithValueMapping first, then from excel_map tag
+func mapValue(value reflect.Value, column column, options *options) (string, bool) {
+	if !value.CanInterface() {
+		return "", false
+	}
+	if mapping, ok := options.valueMappings[column.header]; ok && value.Type().Comparable() {
+		if label, ok := mapping[value.Interface()]; ok {
+			return label, true
+		}
+	}
+	if column.labels != nil {
+		if label, ok := column.labels[fmt.Sprint(value.Interface())]; ok {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// formatBytes converts b to string using excel_bytes tag or options.bytesFormat
+func formatBytes(b []byte, column column, options *options) (string, error) {
+	format := options.bytesFormat
+	if tag, ok := column.field.Tag.Lookup("excel_bytes"); ok {
+		format = BytesFormat(tag)
+	}
+	switch format {
+	case "", BytesFormatString:
+		return string(b), nil
+	case BytesFormatHex:
+		return hex.EncodeToString(b), nil
+	case BytesFormatBase64:
+		return base64.StdEncoding.EncodeToString(b), nil
+	default:
+		return "", fmt.Errorf("unsupported bytes format %s", format)
+	}
+}
+
+// formatRawJSON converts raw to string using options.rawJSONIndent and options.rawJSONMaxLength
+func formatRawJSON(raw []byte, options *options) (string, error) {
+	if options.rawJSONIndent != "" && len(raw) > 0 {
+		buffer := new(bytes.Buffer)
+		if err := json.Indent(buffer, raw, "", options.rawJSONIndent); err != nil {
+			return "", err
+		}
+		raw = buffer.Bytes()
+	}
+	text := string(raw)
+	if options.rawJSONMaxLength > 0 {
+		if runes := []rune(text); len(runes) > options.rawJSONMaxLength {
+			text = string(runes[:options.rawJSONMaxLength]) + "..."
+		}
+	}
+	return text, nil
+}
+
+// formatDuration converts d to string using options.durationFormat
+func formatDuration(d time.Duration, options *options) string {
+	switch options.durationFormat {
+	case DurationFormatClock:
+		sign := ""
+		if d < 0 {
+			sign, d = "-", -d
+		}
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, int64(d/time.Hour), int64(d/time.Minute%60), int64(d/time.Second%60))
+	case DurationFormatSeconds:
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	case DurationFormatHumanized:
+		if d == 0 {
+			return "0s"
+		}
+		sign := ""
+		if d < 0 {
+			sign, d = "-", -d
+		}
+		units := []struct {
+			unit   time.Duration
+			suffix string
+		}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}}
+		parts := make([]string, 0, len(units))
+		for _, u := range units {
+			if n := d / u.unit; n > 0 {
+				parts = append(parts, strconv.FormatInt(int64(n), 10)+u.suffix)
+				d -= n * u.unit
+			}
+		}
+		if len(parts) == 0 { // less than a second
+			return sign + d.String()
+		}
+		return sign + strings.Join(parts, " ")
+	default:
+		return d.String()
 	}
-	return nil
 }
 
 // next code is copied and modified from https://github.com/360EntSecGroup-Skylar/excelize
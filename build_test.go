@@ -1,13 +1,53 @@
 package excelorm
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
+func getRows(t *testing.T, f *excelize.File, sheet string) [][]string {
+	t.Helper()
+	rows, err := f.GetRows(sheet)
+	require.NoError(t, err)
+	return rows
+}
+
+func getCellValue(t *testing.T, f *excelize.File, sheet, cell string) string {
+	t.Helper()
+	value, err := f.GetCellValue(sheet, cell)
+	require.NoError(t, err)
+	return value
+}
+
+func getCellStyle(t *testing.T, f *excelize.File, sheet, cell string) int {
+	t.Helper()
+	styleID, err := f.GetCellStyle(sheet, cell)
+	require.NoError(t, err)
+	return styleID
+}
+
+func getColWidth(t *testing.T, f *excelize.File, sheet, col string) float64 {
+	t.Helper()
+	width, err := f.GetColWidth(sheet, col)
+	require.NoError(t, err)
+	return width
+}
+
 type Sheet1 struct {
 	Col1  string     `excel_header:"string"`
 	Col2  int        `excel_header:"int"`
@@ -149,7 +189,7 @@ func TestWriteExcel(t *testing.T) {
 	require.NoErrorf(t, err, "")
 
 	err = WriteExcelSaveAs("test5.xlsx", models)
-	require.EqualError(t, err, "unsupported type map")
+	require.EqualError(t, err, "sheet sheet6 row 2 column map (field Col1): unsupported type map")
 
 	sheet7 := Sheet7{
 		SubStruct: subStruct{
@@ -159,7 +199,7 @@ func TestWriteExcel(t *testing.T) {
 	models = make([]SheetModel, 0)
 	models = append(models, sheet7)
 	err = WriteExcelSaveAs("test6.xlsx", models)
-	assert.EqualError(t, err, "unsupported type excelorm.subStruct")
+	assert.NoError(t, err)
 }
 
 func TestWithTimeFormatLayout(t *testing.T) {
@@ -197,6 +237,49 @@ func TestWithTimeFormatLayout(t *testing.T) {
 	}
 }
 
+type Sheet46 struct {
+	UTC      time.Time  `excel_header:"utc"`
+	Shanghai time.Time  `excel_header:"shanghai" excel_tz:"Asia/Shanghai"`
+	Deleted  *time.Time `excel_header:"deleted"`
+}
+
+func (Sheet46) SheetName() string {
+	return "sheet46"
+}
+
+type Sheet47 struct {
+	At time.Time `excel_header:"at" excel_tz:"Mars/Olympus"`
+}
+
+func (Sheet47) SheetName() string {
+	return "sheet47"
+}
+
+func TestWithTimeLocation(t *testing.T) {
+	at := time.Date(2024, 1, 31, 16, 30, 0, 0, time.UTC)
+	models := []SheetModel{Sheet46{UTC: at, Shanghai: at}, Sheet46{UTC: at, Shanghai: at, Deleted: &at}}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"utc", "shanghai", "deleted"},
+		{"2024-01-31 16:30:00", "2024-02-01 00:30:00", "-"},
+		{"2024-01-31 16:30:00", "2024-02-01 00:30:00", "2024-01-31 16:30:00"},
+	}, getRows(t, f, "sheet46"))
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	f, err = write(models, WithTimeLocation(newYork), WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"utc", "shanghai", "deleted"},
+		{"2024-01-31 11:30:00", "2024-02-01 00:30:00", "-"}, // excel_tz takes precedence
+		{"2024-01-31 11:30:00", "2024-02-01 00:30:00", "2024-01-31 11:30:00"},
+	}, getRows(t, f, "sheet46"))
+
+	_, err = write([]SheetModel{Sheet47{At: at}})
+	require.EqualError(t, err, "unknown time zone Mars/Olympus")
+}
+
 func TestWithIfNullValue(t *testing.T) {
 	sheet1 := Sheet1{
 		Col1:  "string",
@@ -391,3 +474,1102 @@ func TestAppendNilRow(t *testing.T) {
 	require.EqualError(t, err, "nil reference row append is not allowed")
 
 }
+
+type address struct {
+	City   string `excel_header:"city"`
+	Street string `excel_header:"street"`
+}
+
+type Sheet8 struct {
+	Name    string  `excel_header:"name"`
+	Home    address `excel_header:"home"`
+	Office  address
+	Ignored address `excel_header:"-"`
+}
+
+func (Sheet8) SheetName() string {
+	return "sheet8"
+}
+
+func TestNestedStruct(t *testing.T) {
+	models := []SheetModel{
+		Sheet8{
+			Name:   "foo",
+			Home:   address{City: "Beijing", Street: "Chang'an"},
+			Office: address{City: "Shanghai", Street: "Nanjing"},
+		},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"name", "home.city", "home.street", "Office.city", "Office.street"},
+		{"foo", "Beijing", "Chang'an", "Shanghai", "Nanjing"},
+	}, getRows(t, f, "sheet8"))
+
+	f, err = write(models, WithHeaderSeparator("_"), WithSheetHeaders(Sheet7{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "home_city", "home_street", "Office_city", "Office_street"}, getRows(t, f, "sheet8")[0])
+	assert.Equal(t, [][]string{{"subStruct_field"}}, getRows(t, f, "sheet7"))
+}
+
+type AuditFields struct {
+	CreatedBy string `excel_header:"created_by"`
+	UpdatedBy string `excel_header:"updated_by"`
+}
+
+type auditTime struct {
+	CreatedAt time.Time `excel_header:"created_at"`
+}
+
+type Sheet9 struct {
+	ID int `excel_header:"id"`
+	AuditFields
+	auditTime
+	Owner AuditFields `excel_header:"owner"`
+}
+
+func (Sheet9) SheetName() string {
+	return "sheet9"
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	models := []SheetModel{
+		Sheet9{
+			ID:          1,
+			AuditFields: AuditFields{CreatedBy: "foo", UpdatedBy: "bar"},
+			auditTime:   auditTime{CreatedAt: time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)},
+			Owner:       AuditFields{CreatedBy: "baz"},
+		},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "created_by", "updated_by", "created_at", "owner.created_by", "owner.updated_by"},
+		{"1", "foo", "bar", "2024-01-02 15:04:05", "baz"},
+	}, getRows(t, f, "sheet9"))
+}
+
+type credential struct {
+	User     string `excel_header:"user"`
+	password string
+}
+
+type Sheet49 struct {
+	Name string `excel_header:"name"`
+	credential
+	Home  address    `excel_header:"home"`
+	Owner credential `excel_header:"owner"`
+	note  string
+}
+
+func (Sheet49) SheetName() string {
+	return "sheet49"
+}
+
+func TestUnexportedFields(t *testing.T) {
+	models := []SheetModel{
+		Sheet49{
+			Name:       "foo",
+			credential: credential{User: "bar", password: "secret"},
+			Home:       address{City: "Beijing"},
+			Owner:      credential{User: "baz", password: "secret"},
+			note:       "qux",
+		},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"name", "user", "home.city", "home.street", "owner.user"},
+		{"foo", "bar", "Beijing", "", "baz"},
+	}, getRows(t, f, "sheet49"))
+}
+
+type Sheet10 struct {
+	Tags    []string   `excel_header:"tags"`
+	Scores  []int      `excel_header:"scores" excel_join:";"`
+	Points  [2]float64 `excel_header:"points"`
+	Aliases []*string  `excel_header:"aliases"`
+	Empty   []string   `excel_header:"empty"`
+}
+
+func (Sheet10) SheetName() string {
+	return "sheet10"
+}
+
+func TestSliceField(t *testing.T) {
+	alias := "bar"
+	models := []SheetModel{
+		Sheet10{
+			Tags:    []string{"a", "b", "c"},
+			Scores:  []int{1, 2, 3},
+			Points:  [2]float64{1.5, 2},
+			Aliases: []*string{&alias, nil},
+		},
+	}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a, b, c", "1;2;3", "1.50, 2.00", "bar, -", "-"}, getRows(t, f, "sheet10")[1])
+
+	f, err = write(models, WithSliceDelimiter("|"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a|b|c", "1;2;3", "1.50|2.00", "bar|"}, getRows(t, f, "sheet10")[1])
+}
+
+func TestWithMapFormat(t *testing.T) {
+	models := []SheetModel{
+		Sheet6{Col1: map[string]string{"k2": "v2", "k1": "v1"}},
+		Sheet6{},
+	}
+	_, err := write(models)
+	require.EqualError(t, err, "sheet sheet6 row 2 column map (field Col1): unsupported type map")
+
+	f, err := write(models, WithMapFormat(MapFormatJSON), WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"map"}, {`{"k1":"v1","k2":"v2"}`}, {"-"}}, getRows(t, f, "sheet6"))
+
+	f, err = write(models, WithMapFormat(MapFormatKeyValue))
+	require.NoError(t, err)
+	assert.Equal(t, "k1=v1; k2=v2", getCellValue(t, f, "sheet6", "A2"))
+}
+
+type money int64
+
+func (m money) MarshalExcelCell() (interface{}, error) {
+	return fmt.Sprintf("¥%d.%02d", m/100, m%100), nil
+}
+
+type status int
+
+func (s *status) MarshalExcelCell() (interface{}, error) {
+	switch *s {
+	case 1:
+		return "active", nil
+	case 2:
+		return nil, nil
+	}
+	return nil, errors.New("unknown status")
+}
+
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalExcelCell() (interface{}, error) {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y), nil
+}
+
+type Sheet11 struct {
+	Price    money   `excel_header:"price"`
+	Discount *money  `excel_header:"discount"`
+	Status   status  `excel_header:"status"`
+	Location point   `excel_header:"location"`
+	Previous *status `excel_header:"previous"`
+}
+
+func (Sheet11) SheetName() string {
+	return "sheet11"
+}
+
+func TestCellMarshaler(t *testing.T) {
+	models := []SheetModel{
+		Sheet11{Price: 1234, Status: 1, Location: point{X: 1, Y: 2}},
+		Sheet11{Price: 5, Status: 2},
+	}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"price", "discount", "status", "location", "previous"},
+		{"¥12.34", "-", "active", "(1, 2)", "-"},
+		{"¥0.05", "-", "-", "(0, 0)", "-"},
+	}, getRows(t, f, "sheet11"))
+
+	_, err = write([]SheetModel{Sheet11{Status: 3}})
+	require.EqualError(t, err, "sheet sheet11 row 2 column status (field Status): unknown status")
+}
+
+type version struct {
+	Major, Minor int
+}
+
+func (v *version) String() string {
+	return fmt.Sprintf("v%d.%d", v.Major, v.Minor)
+}
+
+type level int
+
+func (l level) String() string {
+	return "level" + strconv.Itoa(int(l))
+}
+
+type Sheet12 struct {
+	IP      net.IP   `excel_header:"ip"`
+	Version version  `excel_header:"version"`
+	Level   level    `excel_header:"level"`
+	Next    *version `excel_header:"next"`
+}
+
+func (Sheet12) SheetName() string {
+	return "sheet12"
+}
+
+func TestWithStringerFallback(t *testing.T) {
+	models := []SheetModel{
+		Sheet12{IP: net.IPv4(127, 0, 0, 1), Version: version{Major: 1, Minor: 2}, Level: 3},
+	}
+	f, err := write(models, WithStringerFallback())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"ip", "version", "level", "next"},
+		{"127.0.0.1", "v1.2", "level3"},
+	}, getRows(t, f, "sheet12"))
+
+	_, err = write(models)
+	require.EqualError(t, err, "sheet sheet12 row 2 column level (field Level): unsupported type excelorm.level")
+
+	f, err = write(nil, WithSheetHeaders(Sheet12{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ip", "version.Major", "version.Minor", "level", "next.Major", "next.Minor"}, getRows(t, f, "sheet12")[0])
+}
+
+type color struct {
+	R, G, B uint8
+}
+
+func (c color) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)), nil
+}
+
+type weekday int
+
+func (d *weekday) MarshalText() ([]byte, error) {
+	if *d < 0 || *d > 6 {
+		return nil, errors.New("invalid weekday")
+	}
+	return []byte(time.Weekday(*d).String()), nil
+}
+
+type Sheet13 struct {
+	IP      net.IP    `excel_header:"ip"`
+	Color   color     `excel_header:"color"`
+	Weekday weekday   `excel_header:"weekday"`
+	Time    time.Time `excel_header:"time"`
+	Backup  *color    `excel_header:"backup"`
+}
+
+func (Sheet13) SheetName() string {
+	return "sheet13"
+}
+
+func TestTextMarshaler(t *testing.T) {
+	models := []SheetModel{
+		Sheet13{
+			IP:      net.IPv4(192, 168, 1, 1),
+			Color:   color{R: 255, G: 128},
+			Weekday: 1,
+			Time:    time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local),
+		},
+	}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"ip", "color", "weekday", "time", "backup"},
+		{"192.168.1.1", "#ff8000", "Monday", "2024-01-02 15:04:05", "-"},
+	}, getRows(t, f, "sheet13"))
+
+	_, err = write([]SheetModel{Sheet13{Weekday: 7}})
+	require.EqualError(t, err, "sheet sheet13 row 2 column weekday (field Weekday): invalid weekday")
+}
+
+type Sheet14 struct {
+	Elapsed time.Duration  `excel_header:"elapsed"`
+	Timeout *time.Duration `excel_header:"timeout"`
+}
+
+func (Sheet14) SheetName() string {
+	return "sheet14"
+}
+
+func TestWithDurationFormat(t *testing.T) {
+	timeout := -90 * time.Second
+	models := []SheetModel{
+		Sheet14{Elapsed: 26*time.Hour + 2*time.Minute + 3*time.Second, Timeout: &timeout},
+		Sheet14{Elapsed: 1500 * time.Millisecond},
+		Sheet14{Elapsed: 0},
+	}
+	cases := []struct {
+		format   DurationFormat
+		expected [][]string
+	}{
+		{DurationFormatString, [][]string{{"26h2m3s", "-1m30s"}, {"1.5s"}, {"0s"}}},
+		{DurationFormatClock, [][]string{{"26:02:03", "-0:01:30"}, {"0:00:01"}, {"0:00:00"}}},
+		{DurationFormatSeconds, [][]string{{"93723", "-90"}, {"1.5"}, {"0"}}},
+		{DurationFormatHumanized, [][]string{{"1d 2h 2m 3s", "-1m 30s"}, {"1s"}, {"0s"}}},
+	}
+	for _, c := range cases {
+		f, err := write(models, WithDurationFormat(c.format), WithStringerFallback())
+		require.NoError(t, err)
+		rows := getRows(t, f, "sheet14")
+		require.Len(t, rows, 4)
+		for i, expected := range c.expected {
+			assert.Equal(t, expected, rows[i+1])
+		}
+	}
+}
+
+type Sheet15 struct {
+	String  sql.NullString  `excel_header:"string"`
+	Int64   sql.NullInt64   `excel_header:"int64"`
+	Float64 sql.NullFloat64 `excel_header:"float64"`
+	Bool    sql.NullBool    `excel_header:"bool"`
+	Time    sql.NullTime    `excel_header:"time"`
+}
+
+func (Sheet15) SheetName() string {
+	return "sheet15"
+}
+
+func TestSQLNullTypes(t *testing.T) {
+	models := []SheetModel{
+		Sheet15{
+			String:  sql.NullString{String: "foo", Valid: true},
+			Int64:   sql.NullInt64{Int64: 1, Valid: true},
+			Float64: sql.NullFloat64{Float64: 1.5, Valid: true},
+			Bool:    sql.NullBool{Bool: true, Valid: true},
+			Time:    sql.NullTime{Time: time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local), Valid: true},
+		},
+		Sheet15{
+			String: sql.NullString{String: "ignored"},
+		},
+	}
+	f, err := write(models, WithIfNullValue("-"), WithBoolValueAs("yes", "no"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"string", "int64", "float64", "bool", "time"},
+		{"foo", "1", "1.50", "yes", "2024-01-02 15:04:05"},
+		{"-", "-", "-", "-", "-"},
+	}, getRows(t, f, "sheet15"))
+}
+
+// fakeDecimal behaves like shopspring/decimal.Decimal
+type fakeDecimal struct {
+	value *big.Rat
+}
+
+func (d fakeDecimal) String() string {
+	return d.value.RatString()
+}
+
+func (d fakeDecimal) StringFixed(places int32) string {
+	return d.value.FloatString(int(places))
+}
+
+func (d fakeDecimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+type Sheet16 struct {
+	Amount fakeDecimal  `excel_header:"amount"`
+	Fee    *fakeDecimal `excel_header:"fee"`
+}
+
+func (Sheet16) SheetName() string {
+	return "sheet16"
+}
+
+func TestWithDecimalPlaces(t *testing.T) {
+	models := []SheetModel{
+		Sheet16{Amount: fakeDecimal{big.NewRat(123456789, 1000)}},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"123456789/1000"}, getRows(t, f, "sheet16")[1])
+
+	f, err = write(models, WithDecimalPlaces(2), WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"123456.79", "-"}, getRows(t, f, "sheet16")[1])
+}
+
+type Sheet17 struct {
+	Int      big.Int    `excel_header:"int"`
+	IntPtr   *big.Int   `excel_header:"int pointer"`
+	Float    *big.Float `excel_header:"float"`
+	Rat      *big.Rat   `excel_header:"rat"`
+	Overflow *big.Int   `excel_header:"overflow"`
+}
+
+func (Sheet17) SheetName() string {
+	return "sheet17"
+}
+
+func TestBigNumbers(t *testing.T) {
+	overflow, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+	models := []SheetModel{
+		Sheet17{
+			Int:      *big.NewInt(42),
+			Float:    big.NewFloat(1234567.891),
+			Rat:      big.NewRat(1, 3),
+			Overflow: overflow,
+		},
+	}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42", "-", "1234567.89", "0.33", "123456789012345678901234567890"}, getRows(t, f, "sheet17")[1])
+
+	f, err = write(models, WithFloatFmt('e'), WithFloatPrecision(3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42", "", "1.235e+06", "0.333", "123456789012345678901234567890"}, getRows(t, f, "sheet17")[1])
+}
+
+type Sheet19 struct {
+	Raw       []byte `excel_header:"raw"`
+	Hex       []byte `excel_header:"hex" excel_bytes:"hex"`
+	Signature []byte `excel_header:"signature" excel_bytes:"base64"`
+}
+
+func (Sheet19) SheetName() string {
+	return "sheet19"
+}
+
+type Sheet20 struct {
+	Raw []byte `excel_header:"raw" excel_bytes:"binary"`
+}
+
+func (Sheet20) SheetName() string {
+	return "sheet20"
+}
+
+func TestWithBytesFormat(t *testing.T) {
+	models := []SheetModel{
+		Sheet19{Raw: []byte("Hello"), Hex: []byte("Hello"), Signature: []byte("Hello")},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hello", "48656c6c6f", "SGVsbG8="}, getRows(t, f, "sheet19")[1])
+
+	f, err = write(models, WithBytesFormat(BytesFormatBase64))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SGVsbG8=", "48656c6c6f", "SGVsbG8="}, getRows(t, f, "sheet19")[1])
+
+	_, err = write([]SheetModel{Sheet20{Raw: []byte("Hello")}})
+	require.EqualError(t, err, "sheet sheet20 row 2 column raw (field Raw): unsupported bytes format binary")
+}
+
+type Sheet21 struct {
+	Payload json.RawMessage `excel_header:"payload"`
+}
+
+func (Sheet21) SheetName() string {
+	return "sheet21"
+}
+
+func TestRawJSON(t *testing.T) {
+	models := []SheetModel{
+		Sheet21{Payload: json.RawMessage(`{"id":1,"name":"foo"}`)},
+		Sheet21{},
+	}
+	f, err := write(models, WithBytesFormat(BytesFormatHex), WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"payload"}, {`{"id":1,"name":"foo"}`}, {"-"}}, getRows(t, f, "sheet21"))
+
+	f, err = write(models, WithRawJSONIndent("  "))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"id\": 1,\n  \"name\": \"foo\"\n}", getCellValue(t, f, "sheet21", "A2"))
+
+	f, err = write(models, WithRawJSONMaxLength(8))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,...`, getCellValue(t, f, "sheet21", "A2"))
+
+	_, err = write([]SheetModel{Sheet21{Payload: json.RawMessage(`{`)}}, WithRawJSONIndent("  "))
+	require.Error(t, err)
+}
+
+type Sheet22 struct {
+	Status   int     `excel_header:"status" excel_map:"1=active; 2=disabled"`
+	Gender   int8    `excel_header:"gender"`
+	Previous *int    `excel_header:"previous" excel_map:"1=active;2=disabled"`
+	Roles    []int   `excel_header:"roles" excel_map:"1=admin;2=user"`
+	Level    string  `excel_header:"level"`
+	Ratio    float64 `excel_header:"ratio"`
+}
+
+func (Sheet22) SheetName() string {
+	return "sheet22"
+}
+
+func TestWithValueMapping(t *testing.T) {
+	previous := 2
+	models := []SheetModel{
+		Sheet22{Status: 1, Gender: 1, Previous: &previous, Roles: []int{1, 2, 3}, Level: "h", Ratio: 0.5},
+		Sheet22{Status: 3, Gender: 2, Level: "x"},
+	}
+	f, err := write(models,
+		WithValueMapping("gender", map[interface{}]string{int8(1): "male", int8(2): "female"}),
+		WithValueMapping("level", map[interface{}]string{"h": "high", "l": "low"}),
+		WithValueMapping("status", map[interface{}]string{3: "deleted"}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"status", "gender", "previous", "roles", "level", "ratio"},
+		{"active", "male", "disabled", "admin, user, 3", "high", "0.50"},
+		{"deleted", "female", "", "", "x", "0.00"},
+	}, getRows(t, f, "sheet22"))
+}
+
+type node struct {
+	Name   string `excel_header:"name"`
+	Parent *node  `excel_header:"parent"`
+}
+
+type Sheet23 struct {
+	*AuditFields
+	Name     **string  `excel_header:"name"`
+	Home     *address  `excel_header:"home"`
+	Office   **address `excel_header:"office"`
+	Nickname **string  `excel_header:"nickname"`
+}
+
+func (Sheet23) SheetName() string {
+	return "sheet23"
+}
+
+type Sheet24 struct {
+	Node node `excel_header:"node"`
+}
+
+func (Sheet24) SheetName() string {
+	return "sheet24"
+}
+
+func TestDeepPointer(t *testing.T) {
+	name := "foo"
+	namePtr := &name
+	var nickname *string
+	office := &address{City: "Shanghai"}
+	models := []SheetModel{
+		Sheet23{
+			AuditFields: &AuditFields{CreatedBy: "bar"},
+			Name:        &namePtr,
+			Home:        &address{City: "Beijing", Street: "Chang'an"},
+			Office:      &office,
+			Nickname:    &nickname,
+		},
+		Sheet23{},
+	}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"created_by", "updated_by", "name", "home.city", "home.street", "office.city", "office.street", "nickname"},
+		{"bar", "", "foo", "Beijing", "Chang'an", "Shanghai", "", "-"},
+		{"-", "-", "-", "-", "-", "-", "-", "-"},
+	}, getRows(t, f, "sheet23"))
+
+	// self-referential struct is flattened only once
+	f, err = write(nil, WithSheetHeaders(Sheet24{}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"node.name", "node.parent"}}, getRows(t, f, "sheet24"))
+	_, err = write([]SheetModel{Sheet24{Node: node{Name: "child", Parent: &node{Name: "root"}}}})
+	require.EqualError(t, err, "sheet sheet24 row 2 column node.parent (field Parent): unsupported type excelorm.node")
+}
+
+type Sheet25 struct {
+	CreatedAt time.Time    `excel_header:"created_at"`
+	DeletedAt *time.Time   `excel_header:"deleted_at"`
+	History   []time.Time  `excel_header:"history"`
+	CheckedAt sql.NullTime `excel_header:"checked_at"`
+}
+
+func (Sheet25) SheetName() string {
+	return "sheet25"
+}
+
+func TestWithTimeAsNativeExcelDate(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	models := []SheetModel{
+		Sheet25{
+			CreatedAt: createdAt,
+			History:   []time.Time{createdAt},
+			CheckedAt: sql.NullTime{Time: createdAt, Valid: true},
+		},
+	}
+	f, err := write(models, WithTimeAsNativeExcelDate("yyyy/mm/dd hh:mm:ss"), WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024/01/02 15:04:05", "-", "2024-01-02 15:04:05", "2024/01/02 15:04:05"},
+		getRows(t, f, "sheet25")[1])
+	style := getCellStyle(t, f, "sheet25", "A2")
+	assert.NotZero(t, style)
+	assert.Equal(t, style, getCellStyle(t, f, "sheet25", "D2"))
+	assert.Zero(t, getCellStyle(t, f, "sheet25", "C2"))
+
+	f, err = write(models)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02 15:04:05", getCellValue(t, f, "sheet25", "A2"))
+}
+
+func TestWithFloatAsNumber(t *testing.T) {
+	models := []SheetModel{
+		Sheet2{Col3: 1234.5678, Col11: 1.5},
+		Sheet10{Points: [2]float64{1.5, 2}},
+	}
+	f, err := write(models, WithFloatAsNumber("#,##0.00"))
+	require.NoError(t, err)
+	assert.Equal(t, "1,234.57", getCellValue(t, f, "sheet2", "C2"))
+	assert.Equal(t, "1.50", getCellValue(t, f, "sheet2", "K2"))
+	raw, err := f.GetCellValue("sheet2", "C2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1234.5678", raw)
+	style := getCellStyle(t, f, "sheet2", "C2")
+	assert.NotZero(t, style)
+	assert.Equal(t, style, getCellStyle(t, f, "sheet2", "K2"))
+	assert.Zero(t, getCellStyle(t, f, "sheet2", "B2"))
+	assert.Equal(t, "1.50, 2.00", getCellValue(t, f, "sheet10", "C2"))
+
+	_, err = write(models, WithFloatAsNumber(""), WithFloatPrecision(0))
+	require.NoError(t, err)
+}
+
+type Sheet28 struct {
+	Value   interface{}   `excel_header:"value"`
+	Values  []interface{} `excel_header:"values"`
+	Printer fmt.Stringer  `excel_header:"printer"`
+}
+
+func (Sheet28) SheetName() string {
+	return "sheet28"
+}
+
+func TestInterfaceField(t *testing.T) {
+	name := "foo"
+	models := []SheetModel{
+		Sheet28{Value: 1, Values: []interface{}{"a", 1.5, true, nil}, Printer: level(1)},
+		Sheet28{Value: &name, Printer: &version{Major: 1}},
+		Sheet28{Value: time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)},
+		Sheet28{Value: money(100)},
+	}
+	f, err := write(models, WithIfNullValue("-"), WithStringerFallback())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"value", "values", "printer"},
+		{"1", "a, 1.50, true, -", "level1"},
+		{"foo", "-", "v1.0"},
+		{"2024-01-02 15:04:05", "-", "-"},
+		{"¥1.00", "-", "-"},
+	}, getRows(t, f, "sheet28"))
+
+	_, err = write([]SheetModel{Sheet28{Value: make(chan int)}})
+	require.EqualError(t, err, "sheet sheet28 row 2 column value (field Value): unsupported type chan")
+}
+
+func TestWithZeroTimeValue(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	models := []SheetModel{
+		Sheet25{CreatedAt: createdAt, History: []time.Time{{}, createdAt}},
+		Sheet25{DeletedAt: &time.Time{}},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, "0001-01-01 00:00:00", getCellValue(t, f, "sheet25", "A3"))
+
+	f, err = write(models, WithZeroTimeValue("-"), WithTimeAsNativeExcelDate("yyyy-mm-dd"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"created_at", "deleted_at", "history", "checked_at"},
+		{"2024-01-02", "", "-, 2024-01-02 15:04:05"},
+		{"-", "-"},
+	}, getRows(t, f, "sheet25"))
+}
+
+func TestDefaultSheetName(t *testing.T) {
+	// excelize compares sheet names case-insensitively, "sheet1" must not be deleted as the default "Sheet1"
+	f, err := write([]SheetModel{Sheet1{Col1: "foo"}, Sheet2{Col1: "bar"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet1", "sheet2"}, f.GetSheetList())
+	assert.Equal(t, "foo", getCellValue(t, f, "sheet1", "A2"))
+
+	f, err = write([]SheetModel{Sheet2{Col1: "bar"}}, WithSheetHeaders(Sheet1{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet1", "sheet2"}, f.GetSheetList())
+	assert.Equal(t, "string", getCellValue(t, f, "sheet1", "A1"))
+
+	f, err = write([]SheetModel{Sheet2{Col1: "bar"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet2"}, f.GetSheetList())
+}
+
+type Sheet35 struct {
+	Name string `excel_header:"name"`
+	Flag bool   `excel_header:"flag"`
+}
+
+func (Sheet35) SheetName() string {
+	return "sheet35"
+}
+
+func (Sheet35) SheetOptions() []Option {
+	return []Option{
+		WithHeadless(),
+		WithBoolValueAs("Y", "N"),
+		WithAutoFitColumns(),
+		WithFreezePanes("sheet35", "B1"),
+		WithValueMapping("name", map[interface{}]string{"foo": "FOO"}),
+		WithColumnValidation("sheet35", "name", ValidationRule{Type: ValidationTextLength, Max: 20}),
+	}
+}
+
+func TestSheetModelWithOptions(t *testing.T) {
+	models := []SheetModel{
+		Sheet35{Name: "foo", Flag: true},
+		Sheet1{Col1: "foo", Col4: true},
+		Sheet35{Name: "a long name", Flag: false},
+	}
+	f, err := write(models, WithBoolValueAs("yes", "no"), WithFreezeHeader())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"FOO", "Y"}, {"a long name", "N"}}, getRows(t, f, "sheet35"))
+	assert.Equal(t, "foo", getCellValue(t, f, "sheet1", "A2"))
+	assert.Equal(t, "yes", getCellValue(t, f, "sheet1", "D2"))
+	assert.Equal(t, float64(13), getColWidth(t, f, "sheet35", "A"))
+	assert.Equal(t, 9.140625, getColWidth(t, f, "sheet1", "A")) // default width
+	panes, err := f.GetPanes("sheet35")
+	require.NoError(t, err)
+	assert.Equal(t, "B1", panes.TopLeftCell)
+	panes, err = f.GetPanes("sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, "A2", panes.TopLeftCell)
+	validations, err := f.GetDataValidations("sheet35")
+	require.NoError(t, err)
+	assert.Len(t, validations, 1)
+
+	f, err = write(nil, WithSheetHeaders(Sheet35{}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "flag"}}, getRows(t, f, "sheet35"))
+}
+
+func newTemplate(t *testing.T) string {
+	t.Helper()
+	f := excelize.NewFile()
+	_, err := f.NewSheet("report")
+	require.NoError(t, err)
+	require.NoError(t, f.SetCellValue("report", "A1", "Monthly Report"))
+	styleID, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	require.NoError(t, err)
+	require.NoError(t, f.SetCellStyle("report", "A1", "A1", styleID))
+	require.NoError(t, f.SetCellValue("Sheet1", "A1", "cover"))
+	path := filepath.Join(t.TempDir(), "template.xlsx")
+	require.NoError(t, f.SaveAs(path))
+	return path
+}
+
+func TestWriteExcelIntoTemplate(t *testing.T) {
+	models := []SheetModel{
+		Sheet34{Sheet: "report", Name: "foo"},
+		Sheet34{Sheet: "report", Name: "bar"},
+		Sheet2{Col1: "foo", Col2: 1},
+	}
+	buffer, err := WriteExcelIntoTemplate(newTemplate(t), models, WithAnchorCell("report", "B3"), WithAutoFilter(),
+		WithSummaryRow("report", map[string]Aggregate{"name": AggregateCountA}), WithFreezeHeader())
+	require.NoError(t, err)
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sheet1", "report", "sheet2"}, f.GetSheetList())
+	assert.Equal(t, "cover", getCellValue(t, f, "Sheet1", "A1"))
+	assert.Equal(t, [][]string{
+		{"Monthly Report"},
+		nil,
+		{"", "name"},
+		{"", "foo"},
+		{"", "bar"},
+		{"", ""}, // summary formula
+	}, getRows(t, f, "report"))
+	style, err := f.GetStyle(getCellStyle(t, f, "report", "A1"))
+	require.NoError(t, err)
+	assert.True(t, style.Font.Bold) // keep style of template
+	formula, err := f.GetCellFormula("report", "B6")
+	require.NoError(t, err)
+	assert.Equal(t, "COUNTA(B4:B5)", formula)
+	assert.Equal(t, "'report'!$B$3:$B$5", definedNames(f)["report _xlnm._FilterDatabase"])
+	panes, err := f.GetPanes("report")
+	require.NoError(t, err)
+	assert.Equal(t, "A4", panes.TopLeftCell)
+	assert.Equal(t, "foo", getCellValue(t, f, "sheet2", "A2"))
+
+	_, err = WriteExcelIntoTemplate(filepath.Join(t.TempDir(), "not_exist.xlsx"), models)
+	require.Error(t, err)
+	_, err = write(models, WithAnchorCell("sheet31", "B3"))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+func TestWithSubHeaders(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithSubHeaders("sheet30", map[string]string{"balance": "USD"},
+		map[string]string{"name": "full name", "balance": "end of month"}), WithFreezeHeader(),
+		WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateCount}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"name", "balance"},
+		{"", "USD"},
+		{"full name", "end of month"},
+		{"foo", "1.00"},
+		{"bar", "2.00"},
+		{"Total", ""},
+	}, getRows(t, f, "sheet30"))
+	formula, err := f.GetCellFormula("sheet30", "B6")
+	require.NoError(t, err)
+	assert.Equal(t, "COUNT(B4:B5)", formula)
+	panes, err := f.GetPanes("sheet30")
+	require.NoError(t, err)
+	assert.Equal(t, "A4", panes.TopLeftCell)
+
+	_, err = write(models, WithSubHeaders("sheet30", map[string]string{"unknown": "USD"}))
+	assert.EqualError(t, err, "column unknown not found in sheet sheet30")
+}
+
+type Sheet45 struct {
+	ID        int       `excel_header:"id" excel_desc:"order number"`
+	CreatedAt time.Time `excel_header:"created at" excel_desc:"format: 2006-01-02 15:04:05"`
+	Remark    string    `excel_header:"remark"`
+}
+
+func (Sheet45) SheetName() string {
+	return "sheet45"
+}
+
+func TestHeaderComments(t *testing.T) {
+	comments := func(f *excelize.File, sheet string) map[string]string {
+		result := make(map[string]string)
+		list, err := f.GetComments(sheet)
+		require.NoError(t, err)
+		for _, comment := range list {
+			result[comment.Cell] = comment.Text
+		}
+		return result
+	}
+	models := []SheetModel{Sheet45{ID: 1}, Sheet30{Name: "foo"}}
+	f, err := write(models, WithHeaderComments(map[string]string{"remark": "optional", "id": "unique order number"}),
+		WithSheetTitle("sheet45", "orders", nil))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"A2": "unique order number",
+		"B2": "format: 2006-01-02 15:04:05",
+		"C2": "optional",
+	}, comments(f, "sheet45"))
+	assert.Empty(t, comments(f, "sheet30"))
+
+	f, err = write(models[:1], WithTempFileThreshold(0))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A1": "order number", "B1": "format: 2006-01-02 15:04:05"}, comments(f, "sheet45"))
+
+	f, err = write(models[:1], WithHeadless())
+	require.NoError(t, err)
+	assert.Empty(t, comments(f, "sheet45"))
+}
+
+func TestWithRowNumbers(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet30{Name: "bar", Balance: 2},
+		Sheet34{Sheet: "sheet30", Name: "baz"},
+		Sheet1{Col1: "qux"},
+	}
+	f, err := write(models, WithRowNumbers("序号"), WithSheetTitle("sheet30", "accounts", nil),
+		WithSheetCollisionPolicy(SheetCollisionNewHeader))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"accounts"},
+		{"序号", "name", "balance"},
+		{"1", "foo", "1.00"},
+		{"2", "bar", "2.00"},
+		{"序号", "name"},
+		{"1", "baz"},
+	}, getRows(t, f, "sheet30"))
+	assert.Equal(t, "1", getCellValue(t, f, "sheet1", "A2"))
+	assert.Equal(t, "qux", getCellValue(t, f, "sheet1", "B2"))
+
+	f, err = write(models[:2], WithRowNumbers("no"), WithHeadless(), WithTempFileThreshold(0))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"1", "foo", "1.00"}, {"2", "bar", "2.00"}}, getRows(t, f, "sheet30"))
+
+	_, err = write(models[:1], WithRowNumbers("name"))
+	require.EqualError(t, err, "fields RowNumber and Name of excelorm.Sheet30 have the same header name")
+}
+
+func TestParseColumnsCache(t *testing.T) {
+	options := &options{headerSeparator: "."}
+	columns, err := parseColumns(reflect.TypeOf(Sheet30{}), options)
+	require.NoError(t, err)
+	cached, err := parseColumns(reflect.TypeOf(Sheet30{}), options)
+	require.NoError(t, err)
+	assert.Equal(t, columns, cached)
+	assert.Same(t, &columns[0], &cached[0])
+	assert.Nil(t, options.clone().columnPlans)
+}
+
+type wideGroup struct {
+	String string
+	Int    int
+	Float  float64
+	Bool   bool
+	Time   time.Time
+}
+
+type Sheet36 struct {
+	G1, G2, G3, G4, G5, G6, G7, G8, G9, G10 wideGroup
+}
+
+func (Sheet36) SheetName() string {
+	return "sheet36"
+}
+
+func BenchmarkWriteWide(b *testing.B) {
+	group := wideGroup{String: "foo", Int: 1, Float: 1.5, Bool: true, Time: time.Now()}
+	models := make([]SheetModel, 1000)
+	for i := range models {
+		models[i] = Sheet36{group, group, group, group, group, group, group, group, group, group}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := write(models); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteTall(b *testing.B) {
+	models := make([]SheetModel, 100000)
+	for i := range models {
+		models[i] = Sheet30{Name: "foo", Balance: float64(i)}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := write(models); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFormatPrimitive(t *testing.T) {
+	type primitives struct {
+		String  string
+		Bool    bool
+		Int     int
+		Int8    int8
+		Uint16  uint16
+		Float32 float32
+		Float64 float64
+		Labeled int `excel_map:"1=one"`
+		Time    time.Time
+	}
+	model := primitives{String: "foo", Bool: true, Int: -1, Int8: 2, Uint16: 3, Float32: 1.5, Float64: 2.25, Labeled: 1}
+	for _, opts := range [][]Option{
+		nil,
+		{WithIntegerAsString(), WithBoolValueAs("yes", "no"), WithFloatPrecision(1)},
+		{WithFloatAsNumber("")},
+	} {
+		options := &options{floatPrecision: 2, floatFmt: 'f', headerSeparator: "."}
+		for _, opt := range opts {
+			opt(options)
+		}
+		columns, err := parseColumns(reflect.TypeOf(model), options)
+		require.NoError(t, err)
+		for _, column := range columns {
+			assert.Equal(t, column.header != "Labeled" && column.header != "Time", column.primitive, column.header)
+			if !column.primitive {
+				continue
+			}
+			fieldValue := reflect.ValueOf(model).FieldByIndex(column.index)
+			expected, err := formatValue(fieldValue, column, options)
+			require.NoError(t, err)
+			actual := formatPrimitive(fieldValue, options)
+			assert.Equal(t, fmt.Sprint(expected), fmt.Sprint(actual), column.header)
+			_, expectedString := expected.(string)
+			_, actualString := actual.(string)
+			assert.Equal(t, expectedString, actualString, column.header) // number cells are kept
+		}
+	}
+}
+
+func TestValidateModels(t *testing.T) {
+	assert.NoError(t, ValidateModels([]SheetModel{Sheet30{Name: "foo"}}, WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateSum})))
+	assert.True(t, errors.Is(ValidateModels([]SheetModel{Sheet6{Col1: map[string]string{}}}), ErrUnsupportedType))
+	assert.EqualError(t, ValidateModels([]SheetModel{Sheet30{Name: "foo"}}, WithSummaryRow("sheet30", map[string]Aggregate{"unknown": AggregateSum})),
+		"column unknown not found in sheet sheet30")
+	assert.EqualError(t, ValidateModels(nil, WithSheetOrder("missing")), "sheet missing not found")
+}
+
+func TestWithLenientTypes(t *testing.T) {
+	f, err := write([]SheetModel{Sheet6{Col1: map[string]string{"key": "value"}}}, WithLenientTypes())
+	require.NoError(t, err)
+	assert.Equal(t, "map[key:value]", getCellValue(t, f, "sheet6", "A2"))
+
+	f, err = write([]SheetModel{Sheet28{Value: complex(1, 2), Values: []interface{}{complex64(3)}}}, WithLenientTypes())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(1+2i)", "(3+0i)"}, getRows(t, f, "sheet28")[1])
+}
+
+type Sheet39 struct {
+	Name     string `excel_header:"name"`
+	Nickname string `excel_header:"name"`
+	Alias    string `excel_header:"name (2)"`
+}
+
+func (Sheet39) SheetName() string {
+	return "sheet39"
+}
+
+func TestDuplicateHeaders(t *testing.T) {
+	models := []SheetModel{Sheet39{Name: "foo", Nickname: "bar", Alias: "baz"}}
+	_, err := write(models)
+	require.EqualError(t, err, "fields Name and Nickname of excelorm.Sheet39 have the same header name")
+
+	f, err := write(models, WithDedupeHeaders())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "name (2)", "name (2) (2)"}, {"foo", "bar", "baz"}}, getRows(t, f, "sheet39"))
+}
+
+func TestWithCellOverflowPolicy(t *testing.T) {
+	text := strings.Repeat("a", excelize.TotalCellChars) + "bcd"
+	models := []SheetModel{Sheet34{Sheet: "sheet34", Name: text}}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, text[:excelize.TotalCellChars], getCellValue(t, f, "sheet34", "A2"))
+
+	f, err = write(models, WithCellOverflowPolicy(CellOverflowEllipsis))
+	require.NoError(t, err)
+	assert.Equal(t, text[:excelize.TotalCellChars-3]+"...", getCellValue(t, f, "sheet34", "A2"))
+
+	_, err = write(models, WithCellOverflowPolicy(CellOverflowError))
+	require.EqualError(t, err, "sheet sheet34 row 2 column name (field Name): value has 32770 characters, exceeds the limit 32767")
+
+	f, err = write(models, WithCellOverflowPolicy(CellOverflowComment))
+	require.NoError(t, err)
+	assert.Equal(t, text[:excelize.TotalCellChars], getCellValue(t, f, "sheet34", "A2"))
+	comments, err := f.GetComments("sheet34")
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "A2", comments[0].Cell)
+	assert.Equal(t, "bcd", comments[0].Text)
+
+	_, err = write(models, WithCellOverflowPolicy(CellOverflowComment), WithTempFileThreshold(0))
+	require.EqualError(t, err, "CellOverflowComment is not supported with WithTempFileThreshold")
+}
+
+func TestStructRow(t *testing.T) {
+	rowType := reflect.StructOf([]reflect.StructField{
+		{Name: "Name", Type: reflect.TypeOf(""), Tag: `excel_header:"name"`},
+		{Name: "Age", Type: reflect.TypeOf(new(int)), Tag: `excel_header:"age"`},
+	})
+	row := reflect.New(rowType)
+	row.Elem().Field(0).SetString("foo")
+	models := []SheetModel{StructRow("users", row.Interface()), StructRow("users", reflect.New(rowType).Elem().Interface())}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"foo", "-"}, {"", "-"}}, getRows(t, f, "users"))
+
+	assert.Nil(t, StructRow("users", (*Sheet30)(nil)))
+	_, err = write([]SheetModel{StructRow("users", 1)})
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
@@ -0,0 +1,451 @@
+package excelorm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Builder 分批追加数据并生成 excel, 用于数据无法一次性得到的场景, 如从分页接口逐页拉取数据,
+// Append 追加的数据在 Flush 时写入 excel, 之后不再被 Builder 引用;
+// 与 WithTempFileThreshold 同时使用时, 已写入的行保存在流式写入的缓冲区或临时文件中, 每页之间调用 Flush 即可限制内存的峰值,
+// 是否使用流式写入在第一次 Flush 时按已追加的数据判断
+// example usage:
+//
+//	builder, err := excelorm.NewBuilder(excelorm.WithTempFileThreshold(0))
+//	if err != nil {
+//		return err
+//	}
+//	defer builder.Close()
+//	for page := 1; ; page++ {
+//		orders := fetchOrders(page)
+//		if len(orders) == 0 {
+//			break
+//		}
+//		for _, order := range orders {
+//			builder.Append(order)
+//		}
+//		if err = builder.Flush(); err != nil {
+//			return err
+//		}
+//	}
+//	return builder.SaveAs("orders.xlsx")
+type Builder struct {
+	f        *excelize.File
+	template bool            // whether f is a template workbook, see WriteExcelIntoTemplate
+	options  *options        // options of the workbook
+	models   []SheetModel    // models appended but not written
+	flushed  bool            // whether Flush is called, the stream writers are decided at the first time
+	finished bool            // whether the workbook is finished, no more models can be written
+	written  int             // number of models written or skipped
+	skipped  RowErrors       // errors of skipped models, see WithContinueOnError
+	sorted   []sortedModel   // models of the sheets set by WithSortBy, they are sorted and written when finished
+	reserved map[string]bool // sheets of the sorted models, they are created when their first models are held
+}
+
+// BeforeRowHook 在数据写入前调用, index 为数据在 sheetModels 中的位置(从0开始), 返回值代替 model 写入,
+// 如脱敏, 补充计算字段, 返回 nil 时不写入该行, 返回错误时该行写入失败
+type BeforeRowHook func(model SheetModel, index int) (SheetModel, error)
+
+// WithBeforeRowHook 每行数据写入前调用 hook, 可以修改或过滤数据, 错误为包含行信息的 *RowError
+func WithBeforeRowHook(hook BeforeRowHook) Option {
+	return func(options *options) {
+		options.beforeRowHook = hook
+	}
+}
+
+// WithRowFilter 只写入 filter 返回 true 的数据, 在 WithBeforeRowHook 之后调用, 被过滤的数据仍然占用 hook 的 index,
+// 用于从同一份数据生成不同过滤条件的 sheet 或 excel, 而不需要复制 slice
+// example usage:
+//
+//	excelorm.WriteExcelSaveAs("paid.xlsx", models, excelorm.WithRowFilter(func(model excelorm.SheetModel) bool {
+//		return model.(Order).Paid
+//	}))
+func WithRowFilter(filter func(model SheetModel) bool) Option {
+	return func(options *options) {
+		options.rowFilter = filter
+	}
+}
+
+// AfterRowHook 在数据写入后调用, index 为数据在 sheetModels 中的位置(从0开始)
+type AfterRowHook func(model SheetModel, index int) error
+
+// WithAfterRowHook 每行数据写入后调用 hook, 如统计写入的行数, 返回错误时中止写入(或按 WithContinueOnError 记录), 该行已写入
+func WithAfterRowHook(hook AfterRowHook) Option {
+	return func(options *options) {
+		options.afterRowHook = hook
+	}
+}
+
+// WithErrorSheet 跳过写入失败的行(同 WithContinueOnError), 并将其序号, sheet 和错误信息写入名为 sheet 的 sheet 中,
+// 没有跳过的行时不创建该 sheet
+func WithErrorSheet(sheet string) Option {
+	return func(options *options) {
+		options.continueOnError = true
+		options.errorSheet = sanitizeSheetName(sheet)
+	}
+}
+
+// errorRow is a row of the sheet set by WithErrorSheet
+type errorRow struct {
+	sheet string `excel_header:"-"`
+	Index int    `excel_header:"index"`
+	Sheet string `excel_header:"sheet"`
+	Error string `excel_header:"error"`
+}
+
+func (r errorRow) SheetName() string {
+	return r.sheet
+}
+
+// WithContinueOnError 数据行写入失败(如字段类型不支持, sheet 名称不合法)时跳过该行继续写入, 而不是中止,
+// 写入完成后仍然生成 excel, 并返回 RowErrors 描述跳过的行, 可以通过 errors.As 获取
+func WithContinueOnError() Option {
+	return func(options *options) {
+		options.continueOnError = true
+	}
+}
+
+// WithSkipNilRows 跳过为 nil 或 nil 指针的数据, 而不是返回 ErrNilRow, 便于直接写入过滤后可能包含 nil 的结果
+func WithSkipNilRows() Option {
+	return func(options *options) {
+		options.skipNilRows = true
+	}
+}
+
+// NewBuilder 创建 Builder, opts 同 WriteExcelSaveAs
+func NewBuilder(opts ...Option) (*Builder, error) {
+	return newBuilder(excelize.NewFile(), false, opts...)
+}
+
+// newBuilder creates a Builder writing into f, f is a template workbook if template is true
+func newBuilder(f *excelize.File, template bool, opts ...Option) (*Builder, error) {
+	// default options
+	options := &options{
+		timeFormatLayout:  "2006-01-02 15:04:05",
+		floatPrecision:    2,
+		floatFmt:          'f',
+		ifNullValue:       "",
+		headerSeparator:   ".",
+		sliceDelimiter:    ", ",
+		maxRows:           excelize.TotalRows,
+		tempFileThreshold: -1,
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(options)
+	}
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+
+	if options.fontName != "" {
+		if err := f.SetDefaultFont(options.fontName); err != nil {
+			return nil, err
+		}
+	}
+	if err := setDocProperties(f, options); err != nil {
+		return nil, err
+	}
+	if err := replacePlaceholders(f, options); err != nil {
+		return nil, err
+	}
+	options.styleIDs = make(map[cellStyle]int)
+	options.sheetLayouts = make(map[string]*sheetLayout)
+	options.sheetParts = make(map[string]int)
+	options.deduplicateKeys = make(map[string]map[string]bool)
+	options.warnings = make(map[string]bool)
+	options.sparklineRows = new(int)
+	for i, sheetName := range options.sheetOrder { // create sheets in order, they are filled later
+		if i == 0 && !template { // the default sheet is the first one
+			if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := newSheet(f, sheetName); err != nil {
+			return nil, err
+		}
+	}
+	return &Builder{f: f, template: template, options: options}, nil
+}
+
+// validateOptions checks the values of options, all invalid values are returned as OptionErrors
+func validateOptions(options *options) error {
+	var errs OptionErrors
+	if options.timeFormatLayout == "" {
+		errs = append(errs, errors.New("time format layout can not be empty"))
+	}
+	if options.floatPrecision < -1 {
+		errs = append(errs, fmt.Errorf("float precision %d is invalid, it must be -1 or non-negative", options.floatPrecision))
+	}
+	if !strings.ContainsRune("beEfgGxX", rune(options.floatFmt)) {
+		errs = append(errs, fmt.Errorf("float fmt %q is invalid, see strconv.FormatFloat", options.floatFmt))
+	}
+	if options.rowHeight < 0 || options.rowHeight > excelize.MaxRowHeight {
+		errs = append(errs, fmt.Errorf("row height %g out of range [0, %d]", options.rowHeight, excelize.MaxRowHeight))
+	}
+	if options.headerRowHeight < 0 || options.headerRowHeight > excelize.MaxRowHeight {
+		errs = append(errs, fmt.Errorf("header row height %g out of range [0, %d]", options.headerRowHeight, excelize.MaxRowHeight))
+	}
+	if options.headless && options.headerRowHeight != 0 {
+		errs = append(errs, errors.New("WithHeaderRowHeight can not be used with WithHeadless"))
+	}
+	if options.headless && options.headerStyle != nil {
+		errs = append(errs, errors.New("the header style of WithTheme can not be used with WithHeadless"))
+	}
+	if options.headless && len(options.subHeaders) > 0 {
+		errs = append(errs, errors.New("WithSubHeaders can not be used with WithHeadless"))
+	}
+	if options.backLinkText != "" && options.summarySheet == "" {
+		errs = append(errs, errors.New("WithBackLinks can not be used without WithSummarySheet"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Append 追加数据, 数据在 Flush 或生成 excel 时写入, 顺序与追加的顺序相同, WithSortBy 排序的 sheet 除外
+func (b *Builder) Append(sheetModels ...SheetModel) {
+	b.models = append(b.models, sheetModels...)
+}
+
+// Flush 将已追加的数据写入 excel, 返回错误时出错的数据及其后的数据保留, 下次 Flush 时从出错的数据开始写入
+func (b *Builder) Flush() error {
+	if b.finished {
+		return errors.New("excel is already finished")
+	}
+	options := b.options
+	if !b.flushed {
+		streaming, err := useStreamWriter(b.models, options)
+		if err != nil {
+			return err
+		}
+		if streaming && b.template {
+			return errors.New("WriteExcelIntoTemplate is not supported with WithTempFileThreshold")
+		}
+		options.streaming = streaming
+		b.flushed = true
+	}
+	for i, sheetModel := range b.models {
+		sheetName, err := b.writeModel(sheetModel)
+		if err = b.skip(err, sheetName, b.written); err != nil {
+			b.models = b.models[i:] // the written models are not written again by the next Flush
+			return err
+		}
+		b.written++
+		b.models[i] = nil // release the written model
+	}
+	b.models = b.models[:0]
+	return nil
+}
+
+// skip records err of the model at index as skipped if WithContinueOnError is set, or returns it
+func (b *Builder) skip(err error, sheetName string, index int) error {
+	if err == nil {
+		return nil
+	}
+	if !b.options.continueOnError {
+		return err
+	}
+	rowErr, ok := err.(*RowError)
+	if !ok {
+		rowErr = &RowError{Index: index, Sheet: sheetName, Err: err}
+	}
+	b.skipped = append(b.skipped, rowErr)
+	return nil
+}
+
+// writeModel appends sheetModel to its sheet, or holds it until finished if the sheet is sorted, and returns the sheet name
+func (b *Builder) writeModel(sheetModel SheetModel) (string, error) {
+	if sheetModel == nil || reflect.TypeOf(sheetModel).Kind() == reflect.Ptr && reflect.ValueOf(sheetModel).IsNil() {
+		if b.options.skipNilRows {
+			return "", nil
+		}
+		return "", ErrNilRow
+	}
+	if b.options.beforeRowHook != nil {
+		var err error
+		if sheetModel, err = b.options.beforeRowHook(sheetModel, b.written); err != nil {
+			return "", &RowError{Index: b.written, Err: err}
+		}
+		if sheetModel == nil {
+			return "", nil // dropped by the hook
+		}
+	}
+	if b.options.rowFilter != nil && !b.options.rowFilter(sheetModel) {
+		return "", nil
+	}
+	sheetName, err := getSheetName(sheetModel, b.options)
+	if err != nil {
+		return "", err
+	}
+	if len(b.options.sortKeys[sheetName]) > 0 {
+		if !b.reserved[sheetName] { // keep the order of sheets, the rows are written when finished
+			if err = newSheet(b.f, sheetName); err != nil {
+				return sheetName, err
+			}
+			if b.reserved == nil {
+				b.reserved = make(map[string]bool)
+			}
+			b.reserved[sheetName] = true
+		}
+		b.sorted = append(b.sorted, sortedModel{model: sheetModel, sheet: sheetName, index: b.written})
+		return sheetName, nil
+	}
+	return sheetName, b.appendModel(sheetModel, sheetName, b.written)
+}
+
+// appendModel appends sheetModel at index of the models to sheetName
+func (b *Builder) appendModel(sheetModel SheetModel, sheetName string, index int) error {
+	modelKind := valueOfModel(sheetModel).Kind()
+	switch modelKind {
+	case reflect.Struct:
+		if validator, ok := valueAs(reflect.ValueOf(sheetModel), rowValidatorType); ok {
+			if err := validator.(RowValidator).ValidateRow(); err != nil {
+				return &RowError{Index: index, Sheet: sheetName, Err: err}
+			}
+		}
+		if err := appendRow(b.f, sheetName, sheetModel, modelOptions(sheetName, sheetModel, b.options)); err != nil {
+			return err
+		}
+	default:
+		return ErrNotStruct
+	}
+	if b.options.afterRowHook != nil {
+		if err := b.options.afterRowHook(sheetModel, index); err != nil {
+			return &RowError{Index: index, Sheet: sheetName, Err: err}
+		}
+	}
+	return nil
+}
+
+// writeSorted sorts the models held by WithSortBy and writes them
+func (b *Builder) writeSorted() error {
+	if err := sortModels(b.sorted, b.options); err != nil {
+		return err
+	}
+	for i, model := range b.sorted {
+		if err := b.skip(b.appendModel(model.model, model.sheet, model.index), model.sheet, model.index); err != nil {
+			return err
+		}
+		b.sorted[i] = sortedModel{} // release the written model
+	}
+	b.sorted = nil
+	return nil
+}
+
+// finish writes the rest models and applies the options of sheets and workbook
+func (b *Builder) finish() error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	if err := b.writeSorted(); err != nil {
+		return err
+	}
+	b.finished = true
+	f, options := b.f, b.options
+	if options.errorSheet != "" {
+		errorOptions := options.generatedSheetOptions()
+		for _, rowErr := range b.skipped {
+			model := errorRow{sheet: options.errorSheet, Index: rowErr.Index, Sheet: rowErr.Sheet, Error: rowErr.Err.Error()}
+			if err := appendRow(f, options.errorSheet, model, errorOptions); err != nil {
+				return err
+			}
+		}
+	}
+	if err := setNoDataSheetHeaders(f, options); err != nil {
+		return err
+	}
+	for sheet := range options.sheetTitles {
+		if _, ok := options.sheetLayouts[sheet]; !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	for _, sheet := range options.sheetOrder {
+		if _, ok := options.sheetLayouts[sheet]; !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	for sheet := range options.anchorCells {
+		if _, ok := options.sheetLayouts[sheet]; !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	if err := setSheets(f, options); err != nil {
+		return err
+	}
+	// delete default sheet if it is not used, sheet name is case-insensitive
+	sheetIndex, err := f.GetSheetIndex("Sheet1")
+	if err != nil {
+		return err
+	}
+	if _, ok := options.sheetLayouts[f.GetSheetName(sheetIndex)]; !b.template && sheetIndex != -1 && !ok {
+		if err = f.DeleteSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err = addSummarySheet(f, options); err != nil {
+		return err
+	}
+	if err = setSheetVisibility(f, options); err != nil {
+		return err
+	}
+	if err = setDefinedNames(f, options); err != nil {
+		return err
+	}
+	if len(b.skipped) > 0 {
+		return b.skipped
+	}
+	return nil
+}
+
+// WriteTo 写入剩余的数据并将 excel 写入 w, 之后不能再写入数据, 跳过的行见 WithContinueOnError
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	err := b.finishOnce()
+	if err != nil && !isRowErrors(err) {
+		return 0, err
+	}
+	n, writeErr := b.f.WriteTo(w)
+	if writeErr != nil {
+		return n, writeErr
+	}
+	return n, err
+}
+
+// SaveAs 写入剩余的数据并将 excel 保存到本地, 之后不能再写入数据, 跳过的行见 WithContinueOnError
+func (b *Builder) SaveAs(fileName string) error {
+	if fileName == "" {
+		return ErrEmptyFileName
+	}
+	err := b.finishOnce()
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	if saveErr := b.f.SaveAs(fileName); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// finishOnce finishes the workbook if it is not finished, it returns RowErrors if any model is skipped
+func (b *Builder) finishOnce() error {
+	if !b.finished {
+		return b.finish()
+	}
+	if len(b.skipped) > 0 {
+		return b.skipped
+	}
+	return nil
+}
+
+// Close 删除流式写入的临时文件
+func (b *Builder) Close() error {
+	return b.f.Close()
+}
@@ -0,0 +1,207 @@
+package excelorm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestBuilder(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithTempFileThreshold(0)}} {
+		builder, err := NewBuilder(opts...)
+		require.NoError(t, err)
+		builder.Append(Sheet30{Name: "foo", Balance: 1}, Sheet33{Name: "bar", Level: 1})
+		require.NoError(t, builder.Flush())
+		assert.Empty(t, builder.models)
+		builder.Append(Sheet30{Name: "baz", Balance: 2})
+		require.NoError(t, builder.Flush())
+		builder.Append(Sheet30{Name: "qux", Balance: 3}) // written when finished
+		buffer := new(bytes.Buffer)
+		_, err = builder.WriteTo(buffer)
+		require.NoError(t, err)
+		assert.EqualError(t, builder.Flush(), "excel is already finished")
+		require.NoError(t, builder.Close())
+
+		f, err := excelize.OpenReader(buffer)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sheet30", "sheet33"}, f.GetSheetList())
+		assert.Equal(t, [][]string{
+			{"name", "balance"},
+			{"foo", "1.00"},
+			{"baz", "2.00"},
+			{"qux", "3.00"},
+		}, getRows(t, f, "sheet30"))
+		assert.Equal(t, [][]string{{"name", "level"}, {"bar", "1"}}, getRows(t, f, "sheet33"))
+	}
+
+	builder, err := NewBuilder()
+	require.NoError(t, err)
+	builder.Append(nil)
+	assert.EqualError(t, builder.Flush(), "nil reference row append is not allowed")
+
+	// the models written before an error are not written again
+	builder, err = NewBuilder()
+	require.NoError(t, err)
+	builder.Append(Sheet37{Name: "foo", Age: 1}, Sheet37{Age: 2}, Sheet37{Name: "bar", Age: 3})
+	assert.EqualError(t, builder.Flush(), "sheetModels[1]: name is required")
+	assert.EqualError(t, builder.Flush(), "sheetModels[1]: name is required")
+	assert.Len(t, builder.models, 2)
+	assert.Equal(t, [][]string{{"name", "age"}, {"foo", "1"}}, getRows(t, builder.f, "sheet37"))
+}
+
+func TestWithContinueOnError(t *testing.T) {
+	models := []SheetModel{
+		Sheet11{Price: 100, Status: 1},
+		Sheet11{Price: 200, Status: 3}, // unknown status
+		nil,
+		Sheet11{Price: 300, Status: 1},
+	}
+	buffer, err := WriteExcelAsBytesBuffer(models, WithContinueOnError())
+	require.NotNil(t, buffer)
+	var rowErrors RowErrors
+	require.True(t, errors.As(err, &rowErrors))
+	require.Len(t, rowErrors, 2)
+	assert.Equal(t, 1, rowErrors[0].Index)
+	assert.Equal(t, "sheet11", rowErrors[0].Sheet)
+	assert.EqualError(t, rowErrors[0], "sheetModels[1]: sheet sheet11 row 3 column status (field Status): unknown status")
+	assert.Equal(t, 2, rowErrors[1].Index)
+	assert.True(t, errors.Is(rowErrors[1], ErrNilRow))
+	assert.EqualError(t, err, "2 rows are skipped, the first one is "+rowErrors[0].Error())
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	rows := getRows(t, f, "sheet11")
+	require.Len(t, rows, 3)
+	assert.Equal(t, "¥1.00", rows[1][0])
+	assert.Equal(t, "¥3.00", rows[2][0])
+
+	_, err = WriteExcelAsBytesBuffer(models)
+	assert.EqualError(t, err, "sheet sheet11 row 3 column status (field Status): unknown status")
+}
+
+type Sheet37 struct {
+	Name string `excel_header:"name"`
+	Age  int    `excel_header:"age"`
+}
+
+func (Sheet37) SheetName() string {
+	return "sheet37"
+}
+
+func (s Sheet37) ValidateRow() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestRowValidator(t *testing.T) {
+	models := []SheetModel{
+		Sheet37{Name: "foo", Age: 1},
+		Sheet37{Age: 2},
+		Sheet37{Name: "bar", Age: 3},
+	}
+	_, err := write(models)
+	assert.EqualError(t, err, "sheetModels[1]: name is required")
+
+	f, err := write(models, WithErrorSheet("errors"))
+	var rowErrors RowErrors
+	require.True(t, errors.As(err, &rowErrors))
+	assert.Equal(t, RowErrors{{Index: 1, Sheet: "sheet37", Err: errors.New("name is required")}}, rowErrors)
+	assert.Equal(t, []string{"sheet37", "errors"}, f.GetSheetList())
+	assert.Equal(t, [][]string{{"name", "age"}, {"foo", "1"}, {"bar", "3"}}, getRows(t, f, "sheet37"))
+	assert.Equal(t, [][]string{{"index", "sheet", "error"}, {"1", "sheet37", "name is required"}}, getRows(t, f, "errors"))
+
+	f, err = write(models[:1], WithErrorSheet("errors"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet37"}, f.GetSheetList())
+
+	// options of data rows are not applied to the errors sheet
+	models = append(models, Sheet37{Age: 4})
+	f, err = write(models, WithErrorSheet("errors"), WithDeduplicateBy("errors", "sheet"),
+		WithValueMapping("sheet", map[interface{}]string{"sheet37": "people"}),
+		WithRowStyleFunc(func(sheet string, row int, model SheetModel) *excelize.Style {
+			if model.(Sheet37).Age > 2 {
+				return &excelize.Style{Font: &excelize.Font{Bold: true}}
+			}
+			return nil
+		}))
+	require.ErrorAs(t, err, &rowErrors)
+	assert.Equal(t, [][]string{{"index", "sheet", "error"}, {"1", "sheet37", "name is required"}, {"3", "sheet37", "name is required"}},
+		getRows(t, f, "errors"))
+}
+
+func TestRowHooks(t *testing.T) {
+	models := []SheetModel{
+		Sheet37{Name: "foo", Age: 1},
+		Sheet37{Name: "secret", Age: 2},
+		Sheet37{Name: "bar", Age: 3},
+	}
+	var written []int
+	f, err := write(models, WithBeforeRowHook(func(model SheetModel, index int) (SheetModel, error) {
+		row := model.(Sheet37)
+		if row.Name == "secret" {
+			return nil, nil
+		}
+		row.Name = row.Name[:1] + "**"
+		return row, nil
+	}), WithAfterRowHook(func(model SheetModel, index int) error {
+		written = append(written, index)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"f**", "1"}, {"b**", "3"}}, getRows(t, f, "sheet37"))
+	assert.Equal(t, []int{0, 2}, written)
+
+	_, err = write(models, WithAfterRowHook(func(model SheetModel, index int) error {
+		return errors.New("metrics unavailable")
+	}))
+	assert.EqualError(t, err, "sheetModels[0]: metrics unavailable")
+}
+
+func TestWithRowFilter(t *testing.T) {
+	models := []SheetModel{
+		Sheet37{Name: "foo", Age: 1},
+		Sheet37{Name: "bar", Age: 20},
+		Sheet37{Name: "baz", Age: 30},
+	}
+	adult := WithRowFilter(func(model SheetModel) bool {
+		return model.(Sheet37).Age >= 18
+	})
+	var written []int
+	f, err := write(models, adult, WithAfterRowHook(func(model SheetModel, index int) error {
+		written = append(written, index)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}, {"bar", "20"}, {"baz", "30"}}, getRows(t, f, "sheet37"))
+	assert.Equal(t, []int{1, 2}, written)
+
+	// the filter sees the models returned by the hook
+	f, err = write(models, adult, WithBeforeRowHook(func(model SheetModel, index int) (SheetModel, error) {
+		row := model.(Sheet37)
+		row.Age *= 20
+		return row, nil
+	}))
+	require.NoError(t, err)
+	assert.Len(t, getRows(t, f, "sheet37"), 4)
+
+	f, err = write(models, WithRowFilter(func(model SheetModel) bool { return false }), WithSheetHeaders(Sheet37{}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "age"}}, getRows(t, f, "sheet37"))
+}
+
+func TestWithSkipNilRows(t *testing.T) {
+	var missing *Sheet30
+	models := []SheetModel{nil, Sheet30{Name: "foo"}, missing, Sheet30{Name: "bar"}}
+	f, err := write(models, WithSkipNilRows())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "0.00"}, {"bar", "0.00"}}, getRows(t, f, "sheet30"))
+
+	_, err = write(models[1:])
+	assert.True(t, errors.Is(err, ErrNilRow))
+}
@@ -0,0 +1,135 @@
+package excelorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ChartType WithChart 的图表类型
+type ChartType string
+
+const (
+	ChartColumn ChartType = "column" // 柱状图
+	ChartBar    ChartType = "bar"    // 条形图
+	ChartLine   ChartType = "line"   // 折线图
+	ChartPie    ChartType = "pie"    // 饼图
+)
+
+var chartTypes = map[ChartType]excelize.ChartType{
+	ChartColumn: excelize.Col,
+	ChartBar:    excelize.Bar,
+	ChartLine:   excelize.Line,
+	ChartPie:    excelize.Pie,
+}
+
+// ChartSpec WithChart 的图表
+type ChartSpec struct {
+	Type       ChartType // 图表类型
+	Title      string    // 图表标题
+	Categories string    // 分类(X 轴)所在列的表头
+	Values     []string  // 数值所在列的表头, 每列为一个系列, 系列名称为表头, 饼图只使用第一列
+	Cell       string    // 图表左上角的单元格, 默认为表格右侧空一列的第一行
+	Width      uint      // 图表的宽度, 默认为480
+	Height     uint      // 图表的高度, 默认为260
+}
+
+// sheetChart is a chart set by WithChart
+type sheetChart struct {
+	sheet string
+	spec  ChartSpec
+}
+
+// WithChart 按 sheet 的数据区域生成图表, 数值列需要写入为数字, float 类型的列需要同时设置 WithFloatAsNumber, 不支持流式写入;
+// 可以为同一个 sheet 添加多个图表, sheet 没有数据时不生成图表
+// example usage:
+//
+//	excelorm.WithChart("orders", excelorm.ChartSpec{Type: excelorm.ChartColumn, Categories: "month", Values: []string{"amount"}})
+func WithChart(sheet string, spec ChartSpec) Option {
+	return func(options *options) {
+		options.charts = append(options.charts, sheetChart{sheet: sanitizeSheetName(sheet), spec: spec})
+	}
+}
+
+// addCharts adds the charts set by WithChart after the data rows are written
+func addCharts(f *excelize.File, options *options) error {
+	for _, chart := range options.charts {
+		layout, ok := options.sheetLayouts[chart.sheet]
+		if !ok {
+			return fmt.Errorf("sheet %s not found", chart.sheet)
+		}
+		chartType, ok := chartTypes[chart.spec.Type]
+		if !ok {
+			return fmt.Errorf("unsupported chart type %s", chart.spec.Type)
+		}
+		if len(chart.spec.Values) == 0 {
+			return fmt.Errorf("chart of sheet %s has no values", chart.sheet)
+		}
+		categories, ok, err := chartRange(chart.sheet, chart.spec.Categories, options)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue // no data
+		}
+		values := chart.spec.Values
+		if chart.spec.Type == ChartPie {
+			values = values[:1]
+		}
+		series := make([]excelize.ChartSeries, len(values))
+		for i, header := range values {
+			if series[i].Values, _, err = chartRange(chart.sheet, header, options); err != nil {
+				return err
+			}
+			series[i].Categories = categories
+			if layout.headerRows == layout.titleRows {
+				continue // headless
+			}
+			nameCell, err := layout.cellName(layout.columnNumber(header), layout.titleRows+1)
+			if err != nil {
+				return err
+			}
+			series[i].Name = chartRef(chart.sheet, nameCell)
+		}
+		cell := chart.spec.Cell
+		if cell == "" {
+			if cell, err = layout.cellName(len(layout.columns)+2, 1); err != nil {
+				return err
+			}
+		}
+		excelChart := &excelize.Chart{
+			Type:      chartType,
+			Series:    series,
+			Dimension: excelize.ChartDimension{Width: chart.spec.Width, Height: chart.spec.Height},
+		}
+		if chart.spec.Title != "" {
+			excelChart.Title = []excelize.RichTextRun{{Text: chart.spec.Title}}
+		}
+		if err = f.AddChart(chart.sheet, cell, excelChart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chartRange returns the absolute reference of the data cells of the column with header in sheet, such as 'orders'!$B$2:$B$10
+func chartRange(sheet, header string, options *options) (string, bool, error) {
+	rangeRef, ok, err := columnDataRange(sheet, header, options)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	cells := strings.Split(rangeRef, ":")
+	return chartRef(sheet, cells[0]) + ":" + absoluteCell(cells[1]), true, nil
+}
+
+// chartRef returns the absolute reference of cell in sheet
+func chartRef(sheet, cell string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'!" + absoluteCell(cell)
+}
+
+// absoluteCell converts cell such as B2 to $B$2
+func absoluteCell(cell string) string {
+	i := strings.IndexAny(cell, "0123456789")
+	return "$" + cell[:i] + "$" + cell[i:]
+}
@@ -0,0 +1,46 @@
+package excelorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChart(t *testing.T) {
+	models := []SheetModel{Sheet30{Name: "foo", Balance: 1.5}, Sheet30{Name: "bar", Balance: 2}}
+	spec := ChartSpec{Type: ChartColumn, Title: "Balances", Categories: "name", Values: []string{"balance"}}
+	f, err := write(models, WithFloatAsNumber(""), WithChart("sheet30", spec),
+		WithChart("sheet30", ChartSpec{Type: ChartPie, Categories: "name", Values: []string{"balance"}, Cell: "D20"}))
+	require.NoError(t, err)
+	buffer, err := f.WriteToBuffer()
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), "xl/charts/chart2.xml")
+	drawing, ok := f.Pkg.Load("xl/drawings/drawing1.xml")
+	require.True(t, ok)
+	assert.Contains(t, string(drawing.([]byte)), "<xdr:col>3</xdr:col>") // D
+	chart, ok := f.Pkg.Load("xl/charts/chart1.xml")
+	require.True(t, ok)
+	for _, ref := range []string{"$B$1", "$A$2:$A$3", "$B$2:$B$3"} {
+		assert.Contains(t, string(chart.([]byte)), "<f>&#39;sheet30&#39;!"+ref+"</f>")
+	}
+
+	// the chart of a sheet without data is skipped
+	f, err = write(models, WithSheetHeaders(Sheet1{}),
+		WithChart("sheet1", ChartSpec{Type: ChartColumn, Categories: "string", Values: []string{"int"}}), WithChart("sheet30", spec))
+	require.NoError(t, err)
+	buffer, err = f.WriteToBuffer()
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), "xl/charts/chart1.xml")
+	assert.NotContains(t, buffer.String(), "xl/charts/chart2.xml")
+	chart, ok = f.Pkg.Load("xl/charts/chart1.xml")
+	require.True(t, ok)
+	assert.Contains(t, string(chart.([]byte)), "<f>&#39;sheet30&#39;!$B$2:$B$3</f>")
+
+	_, err = write(models, WithChart("sheet30", ChartSpec{Type: ChartLine, Categories: "name", Values: []string{"foo"}}))
+	assert.EqualError(t, err, "column foo not found in sheet sheet30")
+	_, err = write(models, WithChart("sheet30", ChartSpec{Type: "radar", Values: []string{"balance"}}))
+	assert.EqualError(t, err, "unsupported chart type radar")
+	_, err = write(models, WithChart("sheet30", spec), WithTempFileThreshold(0))
+	assert.EqualError(t, err, "WithChart is not supported with WithTempFileThreshold")
+}
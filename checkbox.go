@@ -0,0 +1,68 @@
+package excelorm
+
+import (
+	"reflect"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WithBoolAsCheckbox 将表头为 headers 的 bool 类型的列展示为复选框表单控件, headers 为空时应用于所有 bool 类型的列,
+// 用于生成可勾选的清单; 单元格中保留 TRUE 或 FALSE, 字体颜色与背景色相同, 可以被公式引用,
+// 但 excelize 不支持将复选框链接到单元格, 在 excel 中勾选时单元格的值不会改变;
+// 这些列不使用 WithBoolValueAs 设置的展示内容, 值为 nil 时仍然展示为空值, 不支持流式写入
+func WithBoolAsCheckbox(headers ...string) Option {
+	return func(options *options) {
+		options.checkboxes = true
+		options.checkboxHeaders = headers
+	}
+}
+
+// isCheckboxColumn reports whether column is rendered as checkboxes by WithBoolAsCheckbox
+func isCheckboxColumn(column column, options *options) bool {
+	if !options.checkboxes || indirectType(column.field.Type).Kind() != reflect.Bool {
+		return false
+	}
+	if len(options.checkboxHeaders) == 0 {
+		return true
+	}
+	for _, header := range options.checkboxHeaders {
+		if header == column.header {
+			return true
+		}
+	}
+	return false
+}
+
+// rowCheckboxes returns the checkboxes of the row at line of layout, the values of their cells are replaced by
+// the bools of modelValue and hidden by styles
+func rowCheckboxes(layout *sheetLayout, line int, modelValue reflect.Value, columns []column, values []interface{},
+	styles []cellStyle, options *options) ([]excelize.FormControl, error) {
+	var checkboxes []excelize.FormControl
+	for i, column := range columns {
+		if !isCheckboxColumn(column, options) {
+			continue
+		}
+		fieldValue, ok := fieldByIndex(modelValue, column.index)
+		for ok && fieldValue.Kind() == reflect.Pointer {
+			ok = !fieldValue.IsNil()
+			fieldValue = fieldValue.Elem()
+		}
+		if !ok {
+			continue // null
+		}
+		cellName, err := layout.cellName(i+1, line)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = fieldValue.Bool()
+		styles[i].hidden = true
+		checkboxes = append(checkboxes, excelize.FormControl{
+			Cell:    cellName,
+			Type:    excelize.FormControlCheckBox,
+			Checked: fieldValue.Bool(),
+			Width:   20,
+			Height:  18,
+		})
+	}
+	return checkboxes, nil
+}
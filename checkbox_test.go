@@ -0,0 +1,53 @@
+package excelorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+type Sheet44 struct {
+	Name     string `excel_header:"name"`
+	Done     bool   `excel_header:"done"`
+	Reviewed *bool  `excel_header:"reviewed"`
+}
+
+func (Sheet44) SheetName() string {
+	return "sheet44"
+}
+
+func TestWithBoolAsCheckbox(t *testing.T) {
+	reviewed := true
+	models := []SheetModel{
+		Sheet44{Name: "foo", Done: true, Reviewed: &reviewed},
+		Sheet44{Name: "bar"},
+	}
+	f, err := write(models, WithBoolAsCheckbox(), WithBoolValueAs("yes", "no"), WithIfNullValue("-"))
+	require.NoError(t, err)
+	controls, err := f.GetFormControls("sheet44")
+	require.NoError(t, err)
+	require.Len(t, controls, 3)
+	checked := make(map[string]bool)
+	for _, control := range controls {
+		assert.Equal(t, excelize.FormControlCheckBox, control.Type)
+		checked[control.Cell] = control.Checked
+	}
+	assert.Equal(t, map[string]bool{"B2": true, "C2": true, "B3": false}, checked)
+	assert.Equal(t, [][]string{{"name", "done", "reviewed"}, {"foo", "TRUE", "TRUE"}, {"bar", "FALSE", "-"}}, getRows(t, f, "sheet44"))
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet44", "B3"))
+	require.NoError(t, err)
+	require.NotNil(t, style.Font)
+	assert.Equal(t, "FFFFFF", style.Font.Color)
+
+	f, err = write(models, WithBoolAsCheckbox("reviewed"))
+	require.NoError(t, err)
+	controls, err = f.GetFormControls("sheet44")
+	require.NoError(t, err)
+	require.Len(t, controls, 1)
+	assert.Equal(t, "C2", controls[0].Cell)
+
+	_, err = write(models, WithBoolAsCheckbox(), WithTempFileThreshold(0))
+	require.EqualError(t, err, "WithBoolAsCheckbox is not supported with WithTempFileThreshold")
+}
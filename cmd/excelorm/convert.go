@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/varushsu/excelorm"
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultTimeFormat is the time layout of excelorm if spec.TimeFormat is not set
+const defaultTimeFormat = "2006-01-02 15:04:05"
+
+// toXLSX converts the records in the file at inPath to the excel at outPath
+func toXLSX(s *spec, inPath, inFormat, outPath string) error {
+	records, fields, err := readRecords(inPath, inFormat)
+	if err != nil {
+		return err
+	}
+	s.resolveColumns(fields)
+	if len(s.Columns) == 0 {
+		return fmt.Errorf("no columns in %s", inPath)
+	}
+	rowType := s.rowType()
+	models := make([]excelorm.SheetModel, len(records))
+	for i, record := range records {
+		row := reflect.New(rowType).Elem()
+		for j, column := range s.Columns {
+			value, ok := record[column.Field]
+			if !ok || value == nil || value == "" && column.Type != "string" && column.Type != "" {
+				continue // null
+			}
+			converted, err := convertValue(column, value)
+			if err != nil {
+				return fmt.Errorf("record %d field %s: %w", i+1, column.Field, err)
+			}
+			field := reflect.New(row.Field(j).Type().Elem())
+			field.Elem().Set(reflect.ValueOf(converted))
+			row.Field(j).Set(field)
+		}
+		models[i] = excelorm.StructRow(s.sheetName(), row.Interface())
+	}
+	opts := append(s.options(), excelorm.WithSheetHeaders(excelorm.StructRow(s.sheetName(), reflect.New(rowType).Interface())))
+	return excelorm.WriteExcelSaveAs(outPath, models, opts...)
+}
+
+// readRecords reads the records in the file at path, it returns the fields in the order of CSV header,
+// or sorted for JSON objects
+func readRecords(path, format string) ([]map[string]interface{}, []string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	var records []map[string]interface{}
+	switch format {
+	case formatCSV:
+		reader := csv.NewReader(bufio.NewReader(file))
+		fields, err := reader.Read()
+		if err != nil {
+			return nil, nil, err
+		}
+		for {
+			line, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return records, fields, nil
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			record := make(map[string]interface{}, len(fields))
+			for i, field := range fields {
+				record[field] = line[i]
+			}
+			records = append(records, record)
+		}
+	case formatJSON:
+		decoder := json.NewDecoder(bufio.NewReader(file))
+		decoder.UseNumber()
+		if err = decoder.Decode(&records); err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	default:
+		decoder := json.NewDecoder(bufio.NewReader(file))
+		decoder.UseNumber()
+		for {
+			var record map[string]interface{}
+			if err = decoder.Decode(&record); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+			}
+			records = append(records, record)
+		}
+	}
+	fieldSet := make(map[string]interface{})
+	for _, record := range records {
+		for field := range record {
+			fieldSet[field] = nil
+		}
+	}
+	return records, sortedKeys(fieldSet), nil
+}
+
+// fromXLSX converts the sheet of s in the excel at inPath to the records in the file at outPath
+func fromXLSX(s *spec, inPath, outPath, outFormat string) error {
+	f, err := excelize.OpenFile(inPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sheet := s.Sheet
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return err
+	}
+	headerRow := 0
+	if s.Title != "" {
+		headerRow = 1
+	}
+	if len(rows) <= headerRow {
+		return fmt.Errorf("sheet %s has no header", sheet)
+	}
+	s.resolveColumns(rows[headerRow])
+	indexes := make(map[string]int, len(rows[headerRow])) // column index of each header
+	for i, header := range rows[headerRow] {
+		indexes[header] = i
+	}
+	records := make([][]interface{}, 0, len(rows)-headerRow-1)
+	for i, row := range rows[headerRow+1:] {
+		record := make([]interface{}, len(s.Columns))
+		for j, column := range s.Columns {
+			index, ok := indexes[column.header()]
+			if !ok {
+				return fmt.Errorf("column %s not found in sheet %s", column.header(), sheet)
+			}
+			if index >= len(row) || row[index] == "" || row[index] == s.NullValue && s.NullValue != "" {
+				continue // null
+			}
+			if record[j], err = parseCell(s, column, row[index]); err != nil {
+				return fmt.Errorf("row %d column %s: %w", headerRow+i+2, column.header(), err)
+			}
+		}
+		records = append(records, record)
+	}
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	writer := bufio.NewWriter(out)
+	if err = writeRecords(writer, s.Columns, records, outFormat); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// parseCell parses the text of a cell written by toXLSX to the type of column, times are formatted by the layout of column
+func parseCell(s *spec, column columnSpec, text string) (interface{}, error) {
+	switch column.Type {
+	case "int":
+		return strconv.ParseInt(text, 10, 64)
+	case "float":
+		return strconv.ParseFloat(text, 64)
+	case "bool":
+		return strconv.ParseBool(text)
+	case "time":
+		layout := s.TimeFormat
+		if layout == "" {
+			layout = defaultTimeFormat
+		}
+		t, err := time.Parse(layout, text)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(column.layout()), nil
+	}
+	return text, nil
+}
+
+// writeRecords writes records with fields of columns in format to w, fields of JSON objects are in the order of columns
+func writeRecords(w io.Writer, columns []columnSpec, records [][]interface{}, format string) error {
+	if format == formatCSV {
+		writer := csv.NewWriter(w)
+		line := make([]string, len(columns))
+		for i, column := range columns {
+			line[i] = column.Field
+		}
+		if err := writer.Write(line); err != nil {
+			return err
+		}
+		for _, record := range records {
+			for i, value := range record {
+				line[i] = ""
+				if value != nil {
+					line[i] = fmt.Sprint(value)
+				}
+			}
+			if err := writer.Write(line); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	var buffer bytes.Buffer
+	if format == formatJSON {
+		buffer.WriteString("[\n")
+	}
+	for i, record := range records {
+		buffer.WriteByte('{')
+		for j, value := range record {
+			if j > 0 {
+				buffer.WriteByte(',')
+			}
+			key, _ := json.Marshal(columns[j].Field)
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			buffer.Write(key)
+			buffer.WriteByte(':')
+			buffer.Write(data)
+		}
+		buffer.WriteByte('}')
+		if format == formatJSON && i < len(records)-1 {
+			buffer.WriteByte(',')
+		}
+		buffer.WriteByte('\n')
+		if _, err := w.Write(buffer.Bytes()); err != nil {
+			return err
+		}
+		buffer.Reset()
+	}
+	if format == formatJSON {
+		buffer.WriteString("]\n")
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,84 @@
+// Command excelorm 将 JSON, ND-JSON 或 CSV 转换为带样式的 xlsx, 或将 xlsx 转换回这些格式, 列和样式由 YAML 格式的 spec 描述,
+// 使服务中使用的格式化逻辑也可以用于临时的运维任务
+//
+// usage:
+//
+//	excelorm -spec orders.yaml -in orders.ndjson -out orders.xlsx
+//	excelorm -spec orders.yaml -in orders.xlsx -out orders.csv
+//
+// 输入和输出的格式按扩展名判断: .json 为对象数组, .ndjson 或 .jsonl 为每行一个对象, .csv 的第一行为字段名, .xlsx 为 excel;
+// spec 的格式见 spec 类型, 未指定 -spec 时按输入的字段生成列, 列的类型按 JSON 的值判断
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "excelorm:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses args and converts the input file to the output file
+func run(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("excelorm", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	specPath := flags.String("spec", "", "YAML file of the columns and styles")
+	inPath := flags.String("in", "", "input file, .json, .ndjson, .jsonl, .csv or .xlsx")
+	outPath := flags.String("out", "", "output file, .xlsx if the input is not, otherwise .json, .ndjson, .jsonl or .csv")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *inPath == "" || *outPath == "" {
+		flags.Usage()
+		return errors.New("-in and -out are required")
+	}
+	s := &spec{}
+	if *specPath != "" {
+		var err error
+		if s, err = loadSpec(*specPath); err != nil {
+			return err
+		}
+	}
+	inFormat, outFormat := fileFormat(*inPath), fileFormat(*outPath)
+	switch {
+	case inFormat == "" || outFormat == "":
+		return fmt.Errorf("unsupported file extension of %s or %s", *inPath, *outPath)
+	case inFormat != formatXLSX && outFormat == formatXLSX:
+		return toXLSX(s, *inPath, inFormat, *outPath)
+	case inFormat == formatXLSX && outFormat != formatXLSX:
+		return fromXLSX(s, *inPath, *outPath, outFormat)
+	default:
+		return fmt.Errorf("can not convert %s to %s, one of them must be .xlsx", *inPath, *outPath)
+	}
+}
+
+const (
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+	formatXLSX   = "xlsx"
+)
+
+// fileFormat returns the format of path by its extension, it is empty if the extension is not supported
+func fileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	case ".csv":
+		return formatCSV
+	case ".xlsx":
+		return formatXLSX
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+const testSpec = `
+sheet: orders
+title: Orders
+theme: corporate-blue
+time_format: "2006-01-02 15:04"
+null_value: "-"
+columns:
+  - field: id
+    header: ID
+    type: int
+  - field: name
+    header: Name
+  - field: amount
+    header: Amount
+    type: float
+  - field: created_at
+    header: Created At
+    type: time
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	specPath := writeFile(t, dir, "spec.yaml", testSpec)
+	inPath := writeFile(t, dir, "orders.csv", "id,name,amount,created_at\n1,foo,12.5,2024-01-02T15:04:00Z\n2,bar,,\n")
+	xlsxPath := filepath.Join(dir, "orders.xlsx")
+	require.NoError(t, run([]string{"-spec", specPath, "-in", inPath, "-out", xlsxPath}, io.Discard))
+
+	f, err := excelize.OpenFile(xlsxPath)
+	require.NoError(t, err)
+	rows, err := f.GetRows("orders")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Orders"},
+		{"ID", "Name", "Amount", "Created At"},
+		{"1", "foo", "12.50", "2024-01-02 15:04"},
+		{"2", "bar", "-", "-"},
+	}, rows)
+	require.NoError(t, f.Close())
+
+	jsonPath := filepath.Join(dir, "orders.ndjson")
+	require.NoError(t, run([]string{"-spec", specPath, "-in", xlsxPath, "-out", jsonPath}, io.Discard))
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":"foo","amount":12.5,"created_at":"2024-01-02T15:04:00Z"}
+{"id":2,"name":"bar","amount":null,"created_at":null}
+`, string(data))
+
+	// columns are inferred without spec
+	inPath = writeFile(t, dir, "users.json", `[{"name":"foo","age":18},{"name":"bar","active":true}]`)
+	require.NoError(t, run([]string{"-in", inPath, "-out", xlsxPath}, io.Discard))
+	f, err = excelize.OpenFile(xlsxPath)
+	require.NoError(t, err)
+	rows, err = f.GetRows("Sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"active", "age", "name"}, {"", "18", "foo"}, {"TRUE", "", "bar"}}, rows)
+	require.NoError(t, f.Close())
+
+	assert.EqualError(t, run([]string{"-in", inPath, "-out", jsonPath}, io.Discard),
+		"can not convert "+inPath+" to "+jsonPath+", one of them must be .xlsx")
+	assert.EqualError(t, run([]string{"-in", inPath}, io.Discard), "-in and -out are required")
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/varushsu/excelorm"
+	"gopkg.in/yaml.v3"
+)
+
+// spec describes the sheet converted by the command, such as:
+//
+//	sheet: orders
+//	title: Orders of 2024
+//	theme: corporate-blue
+//	freeze_header: true
+//	auto_filter: true
+//	time_format: "2006-01-02"
+//	columns:
+//	  - field: id
+//	    header: ID
+//	    type: int
+//	  - field: created_at
+//	    header: Created At
+//	    type: time
+//	    layout: "2006-01-02T15:04:05Z07:00"
+type spec struct {
+	Sheet          string       `yaml:"sheet"`            // sheet 名称, 默认为 Sheet1
+	Title          string       `yaml:"title"`            // 表头上方的标题, 见 excelorm.WithSheetTitle
+	Theme          string       `yaml:"theme"`            // 样式主题, minimal, corporate-blue 或 dark
+	FreezeHeader   bool         `yaml:"freeze_header"`    // 是否冻结表头, 见 excelorm.WithFreezeHeader
+	AutoFilter     bool         `yaml:"auto_filter"`      // 是否为表头添加筛选, 见 excelorm.WithAutoFilter
+	AutoFitColumns bool         `yaml:"auto_fit_columns"` // 是否自动调整列宽, 见 excelorm.WithAutoFitColumns
+	TimeFormat     string       `yaml:"time_format"`      // 时间在 excel 中的格式化版图, 见 excelorm.WithTimeFormatLayout
+	FloatPrecision *int         `yaml:"float_precision"`  // 小数保留多少位, 见 excelorm.WithFloatPrecision
+	NullValue      string       `yaml:"null_value"`       // 空值的展示内容, 见 excelorm.WithIfNullValue
+	Columns        []columnSpec `yaml:"columns"`          // 列, 为空时按输入的字段生成
+}
+
+// columnSpec describes a column of spec
+type columnSpec struct {
+	Field  string `yaml:"field"`  // 输入中的字段名, CSV 中为第一行的列名
+	Header string `yaml:"header"` // 表头, 默认为 Field
+	Type   string `yaml:"type"`   // string, int, float, bool 或 time, 为空时按 JSON 的值判断
+	Layout string `yaml:"layout"` // time 类型在输入中的格式化版图, 默认为 time.RFC3339
+}
+
+const defaultSheet = "Sheet1"
+
+var themes = map[string]excelorm.Theme{
+	"minimal":        excelorm.ThemeMinimal,
+	"corporate-blue": excelorm.ThemeCorporateBlue,
+	"dark":           excelorm.ThemeDark,
+}
+
+// loadSpec reads the spec from the YAML file at path
+func loadSpec(path string) (*spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &spec{}
+	if err = yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for i, column := range s.Columns {
+		if column.Field == "" {
+			return nil, fmt.Errorf("field of column %d is empty", i+1)
+		}
+		if _, ok := columnTypes[column.Type]; !ok {
+			return nil, fmt.Errorf("type %s of column %s is not supported", column.Type, column.Field)
+		}
+	}
+	if _, ok := themes[s.Theme]; s.Theme != "" && !ok {
+		return nil, fmt.Errorf("theme %s is not supported", s.Theme)
+	}
+	return s, nil
+}
+
+// sheetName returns the name of the sheet of s
+func (s *spec) sheetName() string {
+	if s.Sheet == "" {
+		return defaultSheet
+	}
+	return s.Sheet
+}
+
+// options returns the excelorm options of s
+func (s *spec) options() []excelorm.Option {
+	var opts []excelorm.Option
+	if theme, ok := themes[s.Theme]; ok {
+		opts = append(opts, excelorm.WithTheme(theme))
+	}
+	if s.Title != "" {
+		opts = append(opts, excelorm.WithSheetTitle(s.sheetName(), s.Title, nil))
+	}
+	if s.FreezeHeader {
+		opts = append(opts, excelorm.WithFreezeHeader())
+	}
+	if s.AutoFilter {
+		opts = append(opts, excelorm.WithAutoFilter())
+	}
+	if s.AutoFitColumns {
+		opts = append(opts, excelorm.WithAutoFitColumns())
+	}
+	if s.TimeFormat != "" {
+		opts = append(opts, excelorm.WithTimeFormatLayout(s.TimeFormat))
+	}
+	if s.FloatPrecision != nil {
+		opts = append(opts, excelorm.WithFloatPrecision(*s.FloatPrecision))
+	}
+	if s.NullValue != "" {
+		opts = append(opts, excelorm.WithIfNullValue(s.NullValue))
+	}
+	return opts
+}
+
+// resolveColumns sets the columns of s by fields if they are not specified, fields are sorted if they are from JSON objects
+func (s *spec) resolveColumns(fields []string) {
+	if len(s.Columns) > 0 {
+		return
+	}
+	for _, field := range fields {
+		s.Columns = append(s.Columns, columnSpec{Field: field})
+	}
+}
+
+// header returns the header of column
+func (c columnSpec) header() string {
+	if c.Header == "" {
+		return c.Field
+	}
+	return c.Header
+}
+
+// layout returns the time layout of column in the input and output files
+func (c columnSpec) layout() string {
+	if c.Layout == "" {
+		return time.RFC3339
+	}
+	return c.Layout
+}
+
+var columnTypes = map[string]reflect.Type{
+	"":       reflect.TypeOf((*interface{})(nil)).Elem(),
+	"string": reflect.TypeOf(""),
+	"int":    reflect.TypeOf(int64(0)),
+	"float":  reflect.TypeOf(float64(0)),
+	"bool":   reflect.TypeOf(false),
+	"time":   reflect.TypeOf(time.Time{}),
+}
+
+// rowType returns the struct type of the rows of s, it has a pointer field for each column, which is nil for null values
+func (s *spec) rowType() reflect.Type {
+	fields := make([]reflect.StructField, len(s.Columns))
+	for i, column := range s.Columns {
+		fields[i] = reflect.StructField{
+			Name: "Column" + strconv.Itoa(i+1),
+			Type: reflect.PointerTo(columnTypes[column.Type]),
+			Tag:  reflect.StructTag("excel_header:" + strconv.Quote(column.header())),
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// convertValue converts value of record to the type of column, strings are parsed for CSV records
+func convertValue(column columnSpec, value interface{}) (interface{}, error) {
+	text, isText := value.(string)
+	if number, ok := value.(json.Number); ok {
+		text, isText = number.String(), true
+	}
+	switch column.Type {
+	case "string":
+		if isText {
+			return text, nil
+		}
+		return fmt.Sprint(value), nil
+	case "int":
+		if isText {
+			return strconv.ParseInt(text, 10, 64)
+		}
+	case "float":
+		if isText {
+			return strconv.ParseFloat(text, 64)
+		}
+	case "bool":
+		if isText {
+			return strconv.ParseBool(text)
+		}
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case "time":
+		if isText {
+			return time.Parse(column.layout(), text)
+		}
+	default:
+		if number, ok := value.(json.Number); ok { // untyped JSON number
+			if n, err := number.Int64(); err == nil {
+				return n, nil
+			}
+			return number.Float64()
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("can not convert %v to %s", value, column.Type)
+}
+
+// sortedKeys returns the keys of record in order
+func sortedKeys(record map[string]interface{}) []string {
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package excelorm
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// TypeConverter 将字段值转换为写入单元格的值, opts 为写入时的格式设置, 使转换结果与其他单元格一致,
+// 返回 nil 时展示为 WithIfNullValue 设置的空值
+type TypeConverter func(value interface{}, opts *Options) (interface{}, error)
+
+// Options 传给 TypeConverter 的格式设置
+type Options struct {
+	TimeFormatLayout string         // WithTimeFormatLayout 设置的时间格式
+	TimeLocation     *time.Location // 时间展示前转换到的时区, 字段的 excel_tz 优先于 WithTimeLocation, 为 nil 时不转换
+	FloatPrecision   int            // WithFloatPrecision 设置的小数位数
+	FloatFmt         byte           // WithFloatFmt 设置的小数格式
+	IfNullValue      string         // WithIfNullValue 设置的空值
+}
+
+// converterOptions returns the Options passed to converters of column
+func converterOptions(column column, options *options) *Options {
+	location := column.location
+	if location == nil {
+		location = options.timeLocation
+	}
+	return &Options{
+		TimeFormatLayout: options.timeFormatLayout,
+		TimeLocation:     location,
+		FloatPrecision:   options.floatPrecision,
+		FloatFmt:         options.floatFmt,
+		IfNullValue:      options.ifNullValue,
+	}
+}
+
+var typeConverters sync.Map // map[reflect.Type]TypeConverter
+
+// RegisterTypeConverter 为类型 t 注册转换函数, 用于支持第三方类型(如 uuid.UUID, civil.Date, timestamppb.Timestamp),
+// 优先级高于其他所有类型处理, 重复注册会覆盖之前的转换函数, converter 为 nil 时取消注册
+// example usage:
+//
+//	excelorm.RegisterTypeConverter(reflect.TypeOf(uuid.UUID{}), func(value interface{}, opts *excelorm.Options) (interface{}, error) {
+//		return value.(uuid.UUID).String(), nil
+//	})
+func RegisterTypeConverter(t reflect.Type, converter TypeConverter) {
+	if converter == nil {
+		typeConverters.Delete(t)
+		return
+	}
+	typeConverters.Store(t, converter)
+}
+
+// lookupTypeConverter returns the converter registered for t
+func lookupTypeConverter(t reflect.Type) (TypeConverter, bool) {
+	converter, ok := typeConverters.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return converter.(TypeConverter), true
+}
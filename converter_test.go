@@ -0,0 +1,66 @@
+package excelorm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type civilDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+type Sheet18 struct {
+	Birthday civilDate  `excel_header:"birthday"`
+	Deadline *civilDate `excel_header:"deadline"`
+	Level    level      `excel_header:"level"`
+}
+
+func (Sheet18) SheetName() string {
+	return "sheet18"
+}
+
+func TestRegisterTypeConverter(t *testing.T) {
+	RegisterTypeConverter(reflect.TypeOf(civilDate{}), func(value interface{}, opts *Options) (interface{}, error) {
+		d := value.(civilDate)
+		if d.Year == 0 {
+			return nil, nil
+		}
+		return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.Local).Format(opts.TimeFormatLayout), nil
+	})
+	var levelOptions *Options
+	RegisterTypeConverter(reflect.TypeOf(level(0)), func(value interface{}, opts *Options) (interface{}, error) {
+		levelOptions = opts
+		if value.(level) < 0 {
+			return nil, errors.New("negative level")
+		}
+		return int(value.(level)), nil
+	})
+	defer RegisterTypeConverter(reflect.TypeOf(civilDate{}), nil)
+	defer RegisterTypeConverter(reflect.TypeOf(level(0)), nil)
+
+	deadline := civilDate{Year: 2024, Month: time.March, Day: 4}
+	models := []SheetModel{
+		Sheet18{Birthday: civilDate{Year: 2000, Month: time.January, Day: 2}, Deadline: &deadline, Level: 3},
+		Sheet18{},
+	}
+	f, err := write(models, WithIfNullValue("-"), WithTimeFormatLayout("2006/01/02"), WithTimeLocation(time.UTC),
+		WithFloatPrecision(3))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"birthday", "deadline", "level"},
+		{"2000/01/02", "2024/03/04", "3"},
+		{"-", "-", "0"},
+	}, getRows(t, f, "sheet18"))
+	assert.Equal(t, &Options{TimeFormatLayout: "2006/01/02", TimeLocation: time.UTC, FloatPrecision: 3, FloatFmt: 'f', IfNullValue: "-"},
+		levelOptions)
+
+	_, err = write([]SheetModel{Sheet18{Level: -1}})
+	require.EqualError(t, err, "sheet sheet18 row 2 column level (field Level): negative level")
+}
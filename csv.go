@@ -0,0 +1,121 @@
+package excelorm
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// CSVOption ConvertExcelToCSV 的选项
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	comma     rune                // 分隔符
+	sheets    map[string]struct{} // 需要转换的 sheet, 为空时转换所有 sheet
+	skipRows  int                 // 每个 sheet 跳过的行数
+	rawValues bool                // 是否写入单元格的原始值
+}
+
+// WithCSVComma 设置 CSV 的分隔符, 默认为 ','
+func WithCSVComma(comma rune) CSVOption {
+	return func(options *csvOptions) {
+		options.comma = comma
+	}
+}
+
+// WithCSVSheets 只转换名为 sheets 的 sheet, 默认转换所有 sheet
+func WithCSVSheets(sheets ...string) CSVOption {
+	return func(options *csvOptions) {
+		for _, sheet := range sheets {
+			options.sheets[sheet] = struct{}{}
+		}
+	}
+}
+
+// WithCSVSkipRows 跳过每个 sheet 开始的 rows 行, 如 WithSheetTitle 设置的标题, 使 CSV 的第一行为表头
+func WithCSVSkipRows(rows int) CSVOption {
+	return func(options *csvOptions) {
+		options.skipRows = rows
+	}
+}
+
+// WithCSVRawValues 写入单元格的原始值, 如日期为序列号, 小数不按数字格式保留位数, 默认写入 excel 中展示的文本
+func WithCSVRawValues() CSVOption {
+	return func(options *csvOptions) {
+		options.rawValues = true
+	}
+}
+
+// ConvertExcelToCSV 将 src 中的每个 sheet 转换为 dstDir 中名为 <sheet>.csv 的文件, 用于导入导出的 excel 的流水线,
+// dstDir 不存在时创建; 单元格为 excel 中展示的文本, 即按 excelorm 的标签和数字格式格式化后的值, 合并的单元格只有左上角有值,
+// 每行的列数与该 sheet 中最长的行相同
+func ConvertExcelToCSV(src, dstDir string, opts ...CSVOption) error {
+	options := &csvOptions{comma: ',', sheets: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(options)
+	}
+	f, err := excelize.OpenFile(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = os.MkdirAll(dstDir, 0o755); err != nil {
+		return err
+	}
+	for _, sheet := range f.GetSheetList() {
+		if _, ok := options.sheets[sheet]; len(options.sheets) > 0 && !ok {
+			continue
+		}
+		if err = convertSheetToCSV(f, sheet, filepath.Join(dstDir, sheet+".csv"), options); err != nil {
+			return fmt.Errorf("sheet %s: %w", sheet, err)
+		}
+	}
+	return nil
+}
+
+// convertSheetToCSV writes the rows of sheet in f to the CSV file at path
+func convertSheetToCSV(f *excelize.File, sheet, path string, options *csvOptions) error {
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: options.rawValues})
+	if err != nil {
+		return err
+	}
+	if options.skipRows >= len(rows) {
+		rows = nil
+	} else if options.skipRows > 0 {
+		rows = rows[options.skipRows:]
+	}
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	buffer := bufio.NewWriter(file)
+	writer := csv.NewWriter(buffer)
+	writer.Comma = options.comma
+	for _, row := range rows {
+		for len(row) < cols {
+			row = append(row, "") // rows are trimmed by GetRows
+		}
+		if err = writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return err
+	}
+	if err = buffer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,35 @@
+package excelorm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertExcelToCSV(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "accounts.xlsx")
+	models := []SheetModel{Sheet40{Name: "foo", Note: new(string)}, Sheet30{Name: "bar", Balance: 1.5}}
+	require.NoError(t, WriteExcelSaveAs(src, models, WithSheetTitle("sheet30", "Accounts", nil), WithBoolValueAs("yes", "no")))
+
+	dst := filepath.Join(dir, "csv")
+	require.NoError(t, ConvertExcelToCSV(src, dst))
+	data, err := os.ReadFile(filepath.Join(dst, "sheet40.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "name,active,note\nfoo,no,\n", string(data))
+	data, err = os.ReadFile(filepath.Join(dst, "sheet30.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "Accounts,\nname,balance\nbar,1.50\n", string(data))
+
+	dst = filepath.Join(dir, "raw")
+	require.NoError(t, ConvertExcelToCSV(src, dst, WithCSVSheets("sheet30"), WithCSVSkipRows(1), WithCSVComma(';'),
+		WithCSVRawValues()))
+	data, err = os.ReadFile(filepath.Join(dst, "sheet30.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "name;balance\nbar;1.50\n", string(data))
+	_, err = os.Stat(filepath.Join(dst, "sheet40.csv"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,75 @@
+package excelorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEmptyFileName   = errors.New("fileName can not be empty")               // 保存的文件名为空
+	ErrNilRow          = errors.New("nil reference row append is not allowed") // 数据为 nil 或 nil 指针
+	ErrEmptySheetName  = errors.New("sheetModel must have a sheet name")       // SheetName() 返回空字符串
+	ErrNotStruct       = errors.New("sheetModel must be struct")               // 数据不是 struct
+	ErrUnsupportedType = errors.New("unsupported type")                        // 字段的类型不支持写入单元格
+)
+
+// CellError 写入单元格时的错误, 包含出错的 sheet, 行号(从1开始), 表头和字段名, 可以通过 errors.As 获取,
+// Err 为原始的错误, 如 ErrUnsupportedType
+type CellError struct {
+	Sheet  string
+	Row    int
+	Column string
+	Field  string
+	Err    error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("sheet %s row %d column %s (field %s): %v", e.Sheet, e.Row, e.Column, e.Field, e.Err)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// RowError 使用 WithContinueOnError 时被跳过的数据行, Index 为数据在 sheetModels 中的位置(从0开始)
+type RowError struct {
+	Index int
+	Sheet string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("sheetModels[%d]: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors 使用 WithContinueOnError 时所有被跳过的数据行
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d rows are skipped, the first one is %v", len(e), e[0])
+}
+
+// OptionErrors 生成 excel 前检查出的所有不合法的选项, 如 WithFloatPrecision(-2), WithTimeFormatLayout("")
+type OptionErrors []error
+
+func (e OptionErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid options: " + strings.Join(messages, "; ")
+}
+
+// isRowErrors reports whether err is RowErrors, the workbook is still written with it
+func isRowErrors(err error) bool {
+	var rowErrors RowErrors
+	return errors.As(err, &rowErrors)
+}
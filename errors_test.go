@@ -0,0 +1,50 @@
+package excelorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	assert.True(t, errors.Is(WriteExcelSaveAs("", nil), ErrEmptyFileName))
+	_, err := write([]SheetModel{nil})
+	assert.True(t, errors.Is(err, ErrNilRow))
+	_, err = write([]SheetModel{Sheet34{Name: "foo"}})
+	assert.True(t, errors.Is(err, ErrEmptySheetName))
+	_, err = write([]SheetModel{&Sheet30{Name: "foo"}})
+	assert.True(t, errors.Is(err, ErrNotStruct))
+	_, err = write([]SheetModel{Sheet6{Col1: map[string]string{"key": "value"}}})
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+	assert.EqualError(t, err, "sheet sheet6 row 2 column map (field Col1): unsupported type map")
+}
+
+func TestCellError(t *testing.T) {
+	models := []SheetModel{Sheet6{Col1: map[string]string{"key": "value"}}}
+	_, err := write(models, WithAnchorCell("sheet6", "B3"))
+	var cellErr *CellError
+	require.True(t, errors.As(err, &cellErr))
+	assert.Equal(t, &CellError{Sheet: "sheet6", Row: 4, Column: "map", Field: "Col1", Err: cellErr.Err}, cellErr)
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+}
+
+func TestOptionErrors(t *testing.T) {
+	_, err := write([]SheetModel{Sheet30{Name: "foo"}},
+		WithTimeFormatLayout(""), WithFloatPrecision(-2), WithFloatFmt('d'), WithHeadless(), WithHeaderRowHeight(500))
+	var optionErrors OptionErrors
+	require.True(t, errors.As(err, &optionErrors))
+	assert.Len(t, optionErrors, 5)
+	assert.EqualError(t, err, "invalid options: time format layout can not be empty; "+
+		"float precision -2 is invalid, it must be -1 or non-negative; float fmt 'd' is invalid, see strconv.FormatFloat; "+
+		"header row height 500 out of range [0, 409]; WithHeaderRowHeight can not be used with WithHeadless")
+
+	_, err = write([]SheetModel{Sheet30{Name: "foo"}}, WithFloatPrecision(-1), WithFloatFmt('g'))
+	assert.NoError(t, err)
+
+	_, err = write([]SheetModel{Sheet30{Name: "foo"}}, WithTheme(ThemeMinimal), WithHeadless())
+	assert.EqualError(t, err, "invalid options: the header style of WithTheme can not be used with WithHeadless")
+	_, err = write([]SheetModel{Sheet30{Name: "foo"}}, WithTheme(Theme{ZebraFillColor: "#F2F2F2"}), WithHeadless())
+	assert.NoError(t, err)
+}
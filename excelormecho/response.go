@@ -0,0 +1,18 @@
+// Package excelormecho 为 echo 提供 excelorm 的响应函数, 使 handler 可以直接返回 excel 下载
+package excelormecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/varushsu/excelorm"
+)
+
+// XLSX 生成 excel 并作为下载 filename 的附件写入 c 的响应, 见 excelorm.ServeExcel, 状态码为 200,
+// 生成失败时不写入响应, 返回的错误由 echo 的 HTTPErrorHandler 处理
+// example usage:
+//
+//	e.GET("/orders", func(c echo.Context) error {
+//		return excelormecho.XLSX(c, "订单.xlsx", orders, excelorm.WithFreezeHeader())
+//	})
+func XLSX(c echo.Context, filename string, sheetModels []excelorm.SheetModel, opts ...excelorm.Option) error {
+	return excelorm.ServeExcel(c.Response(), filename, sheetModels, opts...)
+}
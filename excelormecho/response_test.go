@@ -0,0 +1,44 @@
+package excelormecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/varushsu/excelorm"
+	"github.com/xuri/excelize/v2"
+)
+
+type order struct {
+	ID int `excel_header:"id"`
+}
+
+func (order) SheetName() string {
+	return "orders"
+}
+
+func TestXLSX(t *testing.T) {
+	e := echo.New()
+	e.GET("/orders", func(c echo.Context) error {
+		return XLSX(c, "orders.xlsx", []excelorm.SheetModel{order{ID: 1}})
+	})
+	e.GET("/invalid", func(c echo.Context) error {
+		return XLSX(c, "orders.xlsx", []excelorm.SheetModel{nil})
+	})
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, excelorm.ContentTypeXLSX, recorder.Header().Get("Content-Type"))
+	f, err := excelize.OpenReader(recorder.Body)
+	require.NoError(t, err)
+	rows, err := f.GetRows("orders")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"id"}, {"1"}}, rows)
+
+	recorder = httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/invalid", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -0,0 +1,38 @@
+// Package excelormgin 为 gin 提供 excelorm 的 render.Render, 使 handler 可以直接返回 excel 下载
+package excelormgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+	"github.com/varushsu/excelorm"
+)
+
+// Render 将数据生成 excel 并作为附件写入响应的 render.Render
+type Render struct {
+	Filename    string                // 下载的文件名, 可以包含中文等非 ASCII 字符
+	SheetModels []excelorm.SheetModel // 写入的数据
+	Options     []excelorm.Option     // 同 excelorm.WriteExcelSaveAs 的 opts
+}
+
+var _ render.Render = Render{}
+
+// XLSX 返回下载 filename 的 Render
+// example usage:
+//
+//	func exportOrders(c *gin.Context) {
+//		c.Render(http.StatusOK, excelormgin.XLSX("订单.xlsx", orders, excelorm.WithFreezeHeader()))
+//	}
+func XLSX(filename string, sheetModels []excelorm.SheetModel, opts ...excelorm.Option) Render {
+	return Render{Filename: filename, SheetModels: sheetModels, Options: opts}
+}
+
+// Render 生成 excel 并写入 w, 见 excelorm.ServeExcel, 生成失败时 gin 记录错误并中止请求
+func (r Render) Render(w http.ResponseWriter) error {
+	return excelorm.ServeExcel(w, r.Filename, r.SheetModels, r.Options...)
+}
+
+// WriteContentType 设置 .xlsx 的 Content-Type
+func (r Render) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", excelorm.ContentTypeXLSX)
+}
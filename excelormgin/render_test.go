@@ -0,0 +1,39 @@
+package excelormgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/varushsu/excelorm"
+	"github.com/xuri/excelize/v2"
+)
+
+type order struct {
+	ID int `excel_header:"id"`
+}
+
+func (order) SheetName() string {
+	return "orders"
+}
+
+func TestXLSX(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/orders", func(c *gin.Context) {
+		c.Render(http.StatusOK, XLSX("orders.xlsx", []excelorm.SheetModel{order{ID: 1}}))
+	})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, excelorm.ContentTypeXLSX, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="orders.xlsx"; filename*=UTF-8''orders.xlsx`, recorder.Header().Get("Content-Disposition"))
+	f, err := excelize.OpenReader(recorder.Body)
+	require.NoError(t, err)
+	rows, err := f.GetRows("orders")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"id"}, {"1"}}, rows)
+}
@@ -0,0 +1,46 @@
+// Package excelormgorm 将 gorm 的查询结果分批写入 excel, 独立为子模块以避免 excelorm 依赖 gorm
+package excelormgorm
+
+import (
+	"reflect"
+
+	"github.com/varushsu/excelorm"
+	"gorm.io/gorm"
+)
+
+// BatchSize ExportGorm 每批查询的行数
+const BatchSize = 1000
+
+// ExportGorm 通过 db.FindInBatches 分批查询 dest 类型(如 Order{})的数据并写入 excel, 每批写入后即释放,
+// db 可以带有 Where, Order 等条件, opts 同 excelorm.NewBuilder, 与 excelorm.WithTempFileThreshold(0) 同时使用时内存占用不随行数增长;
+// 返回的 Builder 中数据已全部写入, 由调用方保存并关闭
+// example usage:
+//
+//	builder, err := excelormgorm.ExportGorm(db.Where("status = ?", 1), Order{}, excelorm.WithTempFileThreshold(0))
+//	if err != nil {
+//		return err
+//	}
+//	defer builder.Close()
+//	return builder.SaveAs("orders.xlsx")
+func ExportGorm(db *gorm.DB, dest excelorm.SheetModel, opts ...excelorm.Option) (*excelorm.Builder, error) {
+	if dest == nil || reflect.TypeOf(dest).Kind() != reflect.Struct {
+		return nil, excelorm.ErrNotStruct
+	}
+	builder, err := excelorm.NewBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(dest))) // *[]T, reused by batches
+	result := db.FindInBatches(rows.Interface(), BatchSize, func(tx *gorm.DB, batch int) error {
+		models := rows.Elem()
+		for i := 0; i < models.Len(); i++ {
+			builder.Append(models.Index(i).Interface().(excelorm.SheetModel))
+		}
+		return builder.Flush()
+	})
+	if result.Error != nil {
+		_ = builder.Close()
+		return nil, result.Error
+	}
+	return builder, nil
+}
@@ -0,0 +1,52 @@
+package excelormgorm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/varushsu/excelorm"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type Order struct {
+	ID     int    `excel_header:"id"`
+	Name   string `excel_header:"name"`
+	Status int    `excel_header:"-"`
+}
+
+func (Order) SheetName() string {
+	return "orders"
+}
+
+func TestExportGorm(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Order{}))
+	orders := make([]Order, BatchSize+1)
+	for i := range orders {
+		orders[i] = Order{Name: "foo", Status: i % 2}
+	}
+	require.NoError(t, db.CreateInBatches(orders, 100).Error)
+
+	builder, err := ExportGorm(db.Where("status = ?", 0), Order{}, excelorm.WithTempFileThreshold(0))
+	require.NoError(t, err)
+	defer builder.Close()
+	buffer := new(bytes.Buffer)
+	_, err = builder.WriteTo(buffer)
+	require.NoError(t, err)
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	rows, err := f.GetRows("orders")
+	require.NoError(t, err)
+	require.Len(t, rows, BatchSize/2+2)
+	assert.Equal(t, []string{"id", "name"}, rows[0])
+	assert.Equal(t, []string{"1", "foo"}, rows[1])
+	assert.Equal(t, []string{"1001", "foo"}, rows[len(rows)-1])
+
+	_, err = ExportGorm(db, &Order{})
+	assert.ErrorIs(t, err, excelorm.ErrNotStruct)
+}
@@ -0,0 +1,26 @@
+module github.com/varushsu/excelorm/excelormproto
+
+go 1.18
+
+require (
+	github.com/stretchr/testify v1.9.0
+	github.com/varushsu/excelorm v0.0.0
+	github.com/xuri/excelize/v2 v2.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/richardlehane/mscfb v1.0.4 // indirect
+	github.com/richardlehane/msoleps v1.0.4 // indirect
+	github.com/xuri/efp v0.0.0-20240408161823-9ad904a10d6d // indirect
+	github.com/xuri/nfp v0.0.0-20240318013403-ab9948c2c4a7 // indirect
+	golang.org/x/crypto v0.28.0 // indirect
+	golang.org/x/net v0.30.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
+	google.golang.org/protobuf v1.34.2
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/varushsu/excelorm => ../
@@ -0,0 +1,265 @@
+// Package excelormproto 将 protobuf 消息转换为 excelorm 的 SheetModel, 使 gRPC 服务可以直接导出响应,
+// 独立为子模块以避免 excelorm 依赖 protobuf
+package excelormproto
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/varushsu/excelorm"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Option Models 的选项
+type Option func(*options)
+
+type options struct {
+	jsonNames  bool                                            // 是否使用 JSON 名称作为表头
+	headerFunc func(field protoreflect.FieldDescriptor) string // 按字段返回表头
+}
+
+// WithJSONNames 使用字段的 JSON 名称(如 createdAt)作为表头, 默认为 proto 中的字段名(如 created_at)
+func WithJSONNames() Option {
+	return func(options *options) {
+		options.jsonNames = true
+	}
+}
+
+// WithHeaderFunc 由 fn 的返回值决定字段的表头, 如读取自定义的字段选项, 返回 "-" 时不导出该字段, 返回空字符串时使用默认表头
+func WithHeaderFunc(fn func(field protoreflect.FieldDescriptor) string) Option {
+	return func(options *options) {
+		options.headerFunc = fn
+	}
+}
+
+// Models 将 messages 转换为写入名为 sheet 的 sheet 的 SheetModel, 每个字段为一列, 按字段在 proto 中定义的顺序排列;
+// google.protobuf.Timestamp 和 Duration 同 time.Time 和 time.Duration, wrappers(如 StringValue)同其中的值,
+// 枚举展示为名称, 其它消息和 map 展示为 JSON, repeated 同 slice, 未设置的字段展示为 excelorm.WithIfNullValue 设置的空值
+// example usage:
+//
+//	models, err := excelormproto.Models("orders", messages)
+//	if err != nil {
+//		return err
+//	}
+//	return excelorm.WriteExcelSaveAs("orders.xlsx", models)
+func Models(sheet string, messages []proto.Message, opts ...Option) ([]excelorm.SheetModel, error) {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	layouts := make(map[protoreflect.FullName]*messageLayout)
+	models := make([]excelorm.SheetModel, len(messages))
+	for i, message := range messages {
+		if message == nil {
+			continue // handled by excelorm, see excelorm.WithSkipNilRows
+		}
+		m := message.ProtoReflect()
+		layout, ok := layouts[m.Descriptor().FullName()]
+		if !ok {
+			var err error
+			if layout, err = newMessageLayout(m.Descriptor(), options); err != nil {
+				return nil, err
+			}
+			layouts[m.Descriptor().FullName()] = layout
+		}
+		row, err := layout.row(m)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		models[i] = excelorm.StructRow(sheet, row.Interface())
+	}
+	return models, nil
+}
+
+// messageLayout is the struct type of the rows of a message type
+type messageLayout struct {
+	rowType reflect.Type
+	fields  []protoreflect.FieldDescriptor // field of each struct field
+}
+
+// newMessageLayout creates the layout of message, each field has a pointer struct field, which is nil if it is not set,
+// or a slice struct field for repeated fields
+func newMessageLayout(message protoreflect.MessageDescriptor, options *options) (*messageLayout, error) {
+	layout := &messageLayout{}
+	var structFields []reflect.StructField
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		header := string(field.Name())
+		if options.jsonNames {
+			header = field.JSONName()
+		}
+		if options.headerFunc != nil {
+			if h := options.headerFunc(field); h == "-" {
+				continue
+			} else if h != "" {
+				header = h
+			}
+		}
+		fieldType := reflect.PointerTo(valueType(field))
+		if field.IsList() {
+			fieldType = reflect.SliceOf(valueType(field))
+		}
+		structFields = append(structFields, reflect.StructField{
+			Name: "Field" + strconv.Itoa(i+1),
+			Type: fieldType,
+			Tag:  reflect.StructTag("excel_header:" + strconv.Quote(header)),
+		})
+		layout.fields = append(layout.fields, field)
+	}
+	if len(structFields) == 0 {
+		return nil, fmt.Errorf("message %s has no fields", message.FullName())
+	}
+	layout.rowType = reflect.StructOf(structFields)
+	return layout, nil
+}
+
+// row converts message to a value of the struct type of layout
+func (l *messageLayout) row(message protoreflect.Message) (reflect.Value, error) {
+	row := reflect.New(l.rowType).Elem()
+	for i, field := range l.fields {
+		if field.HasPresence() && !message.Has(field) {
+			continue // not set
+		}
+		value := message.Get(field)
+		if field.IsList() {
+			list := value.List()
+			slice := reflect.MakeSlice(row.Field(i).Type(), 0, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				element, err := convertValue(field, list.Get(j))
+				if err != nil {
+					return row, err
+				}
+				slice = reflect.Append(slice, reflect.ValueOf(element))
+			}
+			row.Field(i).Set(slice)
+			continue
+		}
+		converted, err := convertValue(field, value)
+		if err != nil {
+			return row, err
+		}
+		pointer := reflect.New(row.Field(i).Type().Elem())
+		pointer.Elem().Set(reflect.ValueOf(converted))
+		row.Field(i).Set(pointer)
+	}
+	return row, nil
+}
+
+var (
+	stringType   = reflect.TypeOf("")
+	int64Type    = reflect.TypeOf(int64(0))
+	uint64Type   = reflect.TypeOf(uint64(0))
+	float64Type  = reflect.TypeOf(float64(0))
+	boolType     = reflect.TypeOf(false)
+	bytesType    = reflect.TypeOf([]byte(nil))
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// valueType returns the go type of a single value of field, the elements for repeated fields
+func valueType(field protoreflect.FieldDescriptor) reflect.Type {
+	if field.IsMap() {
+		return stringType
+	}
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return boolType
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return int64Type
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return uint64Type
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return float64Type
+	case protoreflect.BytesKind:
+		return bytesType
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch name := field.Message().FullName(); {
+		case name == "google.protobuf.Timestamp":
+			return timeType
+		case name == "google.protobuf.Duration":
+			return durationType
+		case isWrapper(name):
+			return valueType(field.Message().Fields().ByName("value"))
+		}
+	}
+	return stringType // string, enum names and JSON of messages
+}
+
+// isWrapper reports whether name is a wrapper type such as google.protobuf.StringValue
+func isWrapper(name protoreflect.FullName) bool {
+	switch name {
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return true
+	}
+	return false
+}
+
+// convertValue converts a single value of field to the type returned by valueType
+func convertValue(field protoreflect.FieldDescriptor, value protoreflect.Value) (interface{}, error) {
+	if field.IsMap() {
+		entries := make(map[string]interface{})
+		var err error
+		value.Map().Range(func(key protoreflect.MapKey, v protoreflect.Value) bool {
+			var converted interface{}
+			if converted, err = convertValue(field.MapValue(), v); err != nil {
+				return false
+			}
+			if t, ok := converted.(time.Time); ok {
+				converted = t.Format(time.RFC3339Nano)
+			}
+			entries[key.String()] = converted
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(entries) // keys are sorted
+		return string(data), err
+	}
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return value.Bool(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int(), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return value.Uint(), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float(), nil
+	case protoreflect.BytesKind:
+		return value.Bytes(), nil
+	case protoreflect.StringKind:
+		return value.String(), nil
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name()), nil
+		}
+		return strconv.Itoa(int(value.Enum())), nil // unknown number
+	}
+	message := value.Message()
+	fields := field.Message().Fields()
+	switch name := field.Message().FullName(); {
+	case name == "google.protobuf.Timestamp":
+		seconds, nanos := message.Get(fields.ByName("seconds")).Int(), message.Get(fields.ByName("nanos")).Int()
+		return time.Unix(seconds, nanos).UTC(), nil
+	case name == "google.protobuf.Duration":
+		seconds, nanos := message.Get(fields.ByName("seconds")).Int(), message.Get(fields.ByName("nanos")).Int()
+		return time.Duration(seconds)*time.Second + time.Duration(nanos), nil
+	case isWrapper(name):
+		return convertValue(fields.ByName("value"), message.Get(fields.ByName("value")))
+	}
+	data, err := protojson.Marshal(message.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,135 @@
+package excelormproto
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/varushsu/excelorm"
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// orderDescriptor returns the descriptor of
+//
+//	message Order {
+//		enum Status { UNKNOWN = 0; PAID = 1; }
+//		int64 id = 1;
+//		string order_name = 2;
+//		Status status = 3;
+//		google.protobuf.Timestamp created_at = 4;
+//		google.protobuf.Duration ttl = 5;
+//		google.protobuf.StringValue note = 6;
+//		repeated string tags = 7;
+//	}
+func orderDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	field := func(name string, number int32, fieldType descriptorpb.FieldDescriptorProto_Type, typeName string,
+		label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: fieldType.Enum(),
+			Label: label.Enum(), JsonName: proto.String(jsonName(name))}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("order.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto", "google/protobuf/duration.proto", "google/protobuf/wrappers.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Order"),
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("PAID"), Number: proto.Int32(1)},
+				},
+			}},
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, "", optional),
+				field("order_name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional),
+				field("status", 3, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".test.Order.Status", optional),
+				field("created_at", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp", optional),
+				field("ttl", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Duration", optional),
+				field("note", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.StringValue", optional),
+				field("tags", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", repeated),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	return fd.Messages().ByName("Order")
+}
+
+func jsonName(name string) string {
+	if name == "order_name" {
+		return "orderName"
+	}
+	if name == "created_at" {
+		return "createdAt"
+	}
+	return name
+}
+
+func TestModels(t *testing.T) {
+	descriptor := orderDescriptor(t)
+	fields := descriptor.Fields()
+	paid := dynamicpb.NewMessage(descriptor)
+	paid.Set(fields.ByName("id"), protoreflect.ValueOfInt64(1))
+	paid.Set(fields.ByName("order_name"), protoreflect.ValueOfString("foo"))
+	paid.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+	createdAt := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	paid.Set(fields.ByName("created_at"), protoreflect.ValueOfMessage(timestamppb.New(createdAt).ProtoReflect()))
+	paid.Set(fields.ByName("ttl"), protoreflect.ValueOfMessage(durationpb.New(90*time.Minute).ProtoReflect()))
+	paid.Set(fields.ByName("note"), protoreflect.ValueOfMessage(wrapperspb.String("urgent").ProtoReflect()))
+	tags := paid.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("a"))
+	tags.Append(protoreflect.ValueOfString("b"))
+	unset := dynamicpb.NewMessage(descriptor)
+
+	models, err := Models("orders", []proto.Message{paid, unset})
+	require.NoError(t, err)
+	f, err := excelize.OpenReader(mustWrite(t, models, excelorm.WithIfNullValue("-")))
+	require.NoError(t, err)
+	rows, err := f.GetRows("orders")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "order_name", "status", "created_at", "ttl", "note", "tags"},
+		{"1", "foo", "PAID", "2024-01-02 15:04:05", "1h30m0s", "urgent", "a, b"},
+		{"0", "", "UNKNOWN", "-", "-", "-"},
+	}, rows)
+
+	models, err = Models("orders", []proto.Message{paid}, WithJSONNames(), WithHeaderFunc(func(field protoreflect.FieldDescriptor) string {
+		if field.Name() == "tags" {
+			return "-"
+		}
+		return ""
+	}))
+	require.NoError(t, err)
+	plan, err := excelorm.ExplainLayout(models)
+	require.NoError(t, err)
+	var headers []string
+	for _, column := range plan.Sheets[0].Columns {
+		headers = append(headers, column.Header)
+	}
+	assert.Equal(t, []string{"id", "orderName", "status", "createdAt", "ttl", "note"}, headers)
+}
+
+func mustWrite(t *testing.T, models []excelorm.SheetModel, opts ...excelorm.Option) *bytes.Buffer {
+	t.Helper()
+	buffer, err := excelorm.WriteExcelAsBytesBuffer(models, opts...)
+	require.NoError(t, err)
+	return buffer
+}
@@ -0,0 +1,110 @@
+package excelorm
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// LayoutPlan ExplainLayout 返回的工作簿布局, sheet 的顺序与生成的 excel 相同
+type LayoutPlan struct {
+	Sheets    []SheetPlan
+	Streaming bool // 是否使用流式写入, 见 WithTempFileThreshold
+}
+
+// SheetPlan 单个 sheet 的布局
+type SheetPlan struct {
+	Name       string       // sheet 名称, 已按规则修正
+	ModelType  string       // 第一个写入该 sheet 的数据类型, 如 "main.Order"
+	Range      string       // 表格(包括标题和表头)所在的区域, 如 "A1:C11"
+	HeaderRows int          // 标题和表头的行数
+	DataRows   int          // 数据的行数
+	Columns    []ColumnPlan // 按顺序排列的列
+}
+
+// ColumnPlan 单个列的布局
+type ColumnPlan struct {
+	Name         string // 列名, 如 "A"
+	Header       string // 表头, 嵌套结构体的表头已连接
+	Field        string // 字段路径, 嵌套结构体的字段以"."连接, 如 "Address.City"
+	Type         string // 字段类型, 如 "*time.Time"
+	NumberFormat string // 写入的单元格的 excel 数字格式, 如 WithTimeAsNativeExcelDate 设置的格式, 为空时为常规格式
+}
+
+// ExplainLayout 按 sheetModels 和 opts 计算但不保存 excel, 返回各 sheet 的列, 表头, 字段类型和数字格式等布局,
+// 以便测试和调试时直接断言布局而无需解析生成的 excel; 使用 WithContinueOnError 跳过行时同时返回布局和 RowErrors
+func ExplainLayout(sheetModels []SheetModel, opts ...Option) (*LayoutPlan, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+	builder, err := newBuilder(f, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+	builder.Append(sheetModels...)
+	if err = builder.finish(); err != nil && !isRowErrors(err) {
+		return nil, err
+	}
+	options := builder.options
+	plan := &LayoutPlan{Streaming: options.streaming}
+	for _, sheetName := range f.GetSheetList() {
+		layout, ok := options.sheetLayouts[sheetName]
+		if !ok {
+			continue
+		}
+		sheetPlan, planErr := explainSheet(sheetName, layout)
+		if planErr != nil {
+			return nil, planErr
+		}
+		plan.Sheets = append(plan.Sheets, sheetPlan)
+	}
+	return plan, err
+}
+
+// explainSheet returns the plan of the sheet with layout
+func explainSheet(sheetName string, layout *sheetLayout) (SheetPlan, error) {
+	plan := SheetPlan{
+		Name:       sheetName,
+		HeaderRows: layout.headerRows,
+		DataRows:   layout.rows - layout.headerRows,
+		Columns:    make([]ColumnPlan, len(layout.columns)),
+	}
+	if layout.modelType != nil {
+		plan.ModelType = layout.modelType.String()
+	}
+	if len(layout.columns) > 0 && layout.rows > 0 {
+		hCell, err := layout.cellName(1, 1)
+		if err != nil {
+			return plan, err
+		}
+		vCell, err := layout.cellName(len(layout.columns), layout.rows)
+		if err != nil {
+			return plan, err
+		}
+		plan.Range = hCell + ":" + vCell
+	}
+	for i, column := range layout.columns {
+		plan.Columns[i] = ColumnPlan{
+			Name:         layout.colNames[i],
+			Header:       column.header,
+			Field:        fieldPath(layout.modelType, column.index),
+			Type:         column.field.Type.String(),
+			NumberFormat: layout.numberFormats[i],
+		}
+	}
+	return plan, nil
+}
+
+// fieldPath returns the names of the fields at index of modelType joined with "."
+func fieldPath(modelType reflect.Type, index []int) string {
+	if modelType == nil {
+		return ""
+	}
+	names := make([]string, len(index))
+	for i, fieldIndex := range index {
+		field := indirectType(modelType).Field(fieldIndex)
+		names[i] = field.Name
+		modelType = field.Type
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,42 @@
+package excelorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainLayout(t *testing.T) {
+	models := []SheetModel{
+		Sheet25{CreatedAt: time.Now()},
+		Sheet30{Name: "foo"},
+		Sheet30{Name: "bar"},
+	}
+	plan, err := ExplainLayout(models, WithTimeAsNativeExcelDate(""), WithAnchorCell("sheet30", "B2"),
+		WithSheetTitle("sheet30", "accounts", nil))
+	require.NoError(t, err)
+	require.Len(t, plan.Sheets, 2)
+	assert.Equal(t, SheetPlan{
+		Name:       "sheet30",
+		ModelType:  "excelorm.Sheet30",
+		Range:      "B2:C5",
+		HeaderRows: 2,
+		DataRows:   2,
+		Columns: []ColumnPlan{
+			{Name: "B", Header: "name", Field: "Name", Type: "string"},
+			{Name: "C", Header: "balance", Field: "Balance", Type: "float64"},
+		},
+	}, plan.Sheets[1])
+	sheet25 := plan.Sheets[0]
+	assert.Equal(t, "A1:D2", sheet25.Range)
+	assert.Equal(t, ColumnPlan{Name: "A", Header: "created_at", Field: "CreatedAt", Type: "time.Time", NumberFormat: "yyyy-mm-dd hh:mm:ss"},
+		sheet25.Columns[0])
+	assert.Equal(t, "*time.Time", sheet25.Columns[1].Type)
+
+	plan, err = ExplainLayout([]SheetModel{Sheet36{}})
+	require.NoError(t, err)
+	assert.Equal(t, "G10.Time", plan.Sheets[0].Columns[49].Field)
+	assert.Equal(t, "G10.Time", plan.Sheets[0].Columns[49].Header)
+}
@@ -0,0 +1,54 @@
+package excelorm
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFS 可以创建文件的文件系统, 如测试中的内存文件系统, 挂载或虚拟的文件系统, 与只读的 fs.FS 相对
+type WriteFS interface {
+	Create(name string) (io.WriteCloser, error)
+}
+
+// WriteFSFunc 将函数转换为 WriteFS, 如 afero.Fs 可以通过以下方式使用:
+//
+//	excelorm.WriteFSFunc(func(name string) (io.WriteCloser, error) {
+//		return fs.Create(name)
+//	})
+type WriteFSFunc func(name string) (io.WriteCloser, error)
+
+func (fn WriteFSFunc) Create(name string) (io.WriteCloser, error) {
+	return fn(name)
+}
+
+// DirFS 返回写入本地目录 dir 的 WriteFS, 同 os.DirFS, name 为 dir 下的相对路径, 父目录需要已经存在
+func DirFS(dir string) WriteFS {
+	return WriteFSFunc(func(name string) (io.WriteCloser, error) {
+		return os.Create(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// WriteExcelToFS 生成 excel 并写入 fsys 中的 path, 用法同 WriteExcelSaveAs, 跳过的行见 WithContinueOnError, 此时仍然写入
+func WriteExcelToFS(fsys WriteFS, path string, sheetModels []SheetModel, opts ...Option) error {
+	if path == "" {
+		return ErrEmptyFileName
+	}
+	f, err := write(sheetModels, opts...)
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	defer f.Close() // remove temp files of stream writers
+	file, createErr := fsys.Create(path)
+	if createErr != nil {
+		return createErr
+	}
+	if _, writeErr := f.WriteTo(file); writeErr != nil {
+		_ = file.Close()
+		return writeErr
+	}
+	if closeErr := file.Close(); closeErr != nil {
+		return closeErr
+	}
+	return err // rows skipped by WithContinueOnError
+}
@@ -0,0 +1,43 @@
+package excelorm
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// memoryFile is a file of memoryFS, it is saved when it is closed
+type memoryFile struct {
+	bytes.Buffer
+	name  string
+	files map[string][]byte
+}
+
+func (f *memoryFile) Close() error {
+	f.files[f.name] = f.Bytes()
+	return nil
+}
+
+func TestWriteExcelToFS(t *testing.T) {
+	files := make(map[string][]byte)
+	fsys := WriteFSFunc(func(name string) (io.WriteCloser, error) {
+		return &memoryFile{name: name, files: files}, nil
+	})
+	models := []SheetModel{Sheet30{Name: "foo"}}
+	require.NoError(t, WriteExcelToFS(fsys, "reports/accounts.xlsx", models))
+	f, err := excelize.OpenReader(bytes.NewReader(files["reports/accounts.xlsx"]))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "0.00"}}, getRows(t, f, "sheet30"))
+
+	dir := t.TempDir()
+	require.NoError(t, WriteExcelToFS(DirFS(dir), "accounts.xlsx", models))
+	_, err = os.Stat(filepath.Join(dir, "accounts.xlsx"))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, WriteExcelToFS(fsys, "", models), ErrEmptyFileName)
+}
@@ -0,0 +1,60 @@
+package excelorm
+
+import (
+	"context"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SheetsClient Google Sheets API 的写入接口, 由调用方注入客户端, excelorm 不依赖 Google API 的 SDK;
+// UpdateValues 将 values 从 A1 开始写入 spreadsheetID 中名为 sheet 的工作表, 工作表需要已经存在,
+// 如使用 google.golang.org/api/sheets/v4 的实现:
+//
+//	func (c client) UpdateValues(ctx context.Context, spreadsheetID, sheet string, values [][]interface{}) error {
+//		_, err := c.service.Spreadsheets.Values.Update(spreadsheetID, "'"+sheet+"'!A1", &sheets.ValueRange{Values: values}).
+//			ValueInputOption("USER_ENTERED").Context(ctx).Do()
+//		return err
+//	}
+//
+// 使用 USER_ENTERED 时数字和日期按用户输入的方式解析, RAW 时全部作为文本
+type SheetsClient interface {
+	UpdateValues(ctx context.Context, spreadsheetID, sheet string, values [][]interface{}) error
+}
+
+// ExportGoogleSheets 将 sheetModels 写入 Google Sheets 中 spreadsheetID 对应的表格, 用法同 WriteExcelSaveAs,
+// 每个 sheet 写入同名的工作表, 单元格为 excel 中展示的文本, 即按标签和 options 格式化后的值, 样式, 公式, 图片等不会写入;
+// 只写入数据 sheet 及 WithSummarySheet, WithErrorSheet 生成的 sheet, 不写入内部的隐藏 sheet(如迷你图的数据);
+// 跳过的行见 WithContinueOnError, 此时仍然写入
+func ExportGoogleSheets(ctx context.Context, client SheetsClient, spreadsheetID string, sheetModels []SheetModel,
+	opts ...Option) error {
+	builder, err := newBuilder(excelize.NewFile(), false, opts...)
+	if err != nil {
+		return err
+	}
+	builder.Append(sheetModels...)
+	if err = builder.finish(); err != nil && !isRowErrors(err) {
+		return err
+	}
+	f := builder.f
+	defer f.Close() // remove temp files of stream writers
+	for _, sheet := range f.GetSheetList() {
+		if _, ok := builder.options.sheetLayouts[sheet]; !ok {
+			continue // internal sheets such as sparklineSheet
+		}
+		rows, getErr := f.GetRows(sheet)
+		if getErr != nil {
+			return getErr
+		}
+		values := make([][]interface{}, len(rows))
+		for i, row := range rows {
+			values[i] = make([]interface{}, len(row))
+			for j, cell := range row {
+				values[i][j] = cell
+			}
+		}
+		if updateErr := client.UpdateValues(ctx, spreadsheetID, sheet, values); updateErr != nil {
+			return updateErr
+		}
+	}
+	return err // rows skipped by WithContinueOnError
+}
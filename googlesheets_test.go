@@ -0,0 +1,43 @@
+package excelorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSheetsClient records the values of each sheet
+type fakeSheetsClient struct {
+	spreadsheetID string
+	values        map[string][][]interface{}
+	err           error
+}
+
+func (c *fakeSheetsClient) UpdateValues(_ context.Context, spreadsheetID, sheet string, values [][]interface{}) error {
+	c.spreadsheetID = spreadsheetID
+	c.values[sheet] = values
+	return c.err
+}
+
+func TestExportGoogleSheets(t *testing.T) {
+	client := &fakeSheetsClient{values: make(map[string][][]interface{})}
+	models := []SheetModel{Sheet30{Name: "foo", Balance: 1.5}}
+	require.NoError(t, ExportGoogleSheets(context.Background(), client, "spreadsheet", models))
+	assert.Equal(t, "spreadsheet", client.spreadsheetID)
+	assert.Equal(t, [][]interface{}{{"name", "balance"}, {"foo", "1.50"}}, client.values["sheet30"])
+
+	// internal sheets are not exported
+	client = &fakeSheetsClient{values: make(map[string][][]interface{})}
+	models = []SheetModel{Sheet42{Name: "foo", Trend: Sparkline{Values: []float64{1, 3, 2}}}}
+	require.NoError(t, ExportGoogleSheets(context.Background(), client, "spreadsheet", models, WithSummarySheet("index")))
+	assert.Equal(t, map[string][][]interface{}{
+		"index":   {{"sheet", "rows"}, {"sheet42", "1"}},
+		"sheet42": {{"name", "trend"}, {"foo"}},
+	}, client.values)
+
+	client.err = errors.New("quota exceeded")
+	assert.ErrorIs(t, ExportGoogleSheets(context.Background(), client, "spreadsheet", models), client.err)
+}
@@ -0,0 +1,104 @@
+package excelorm
+
+import (
+	"bufio"
+	"html"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteHTMLTable 按 sheetModels 和 opts 生成 excel 并将每个 sheet 渲染为一个 HTML 表格写入 w, 用于邮件正文和导出的网页预览;
+// 表头和单元格为 excel 中展示的文本, 即按标签和 options 格式化后的值, WithSheetTitle 设置的标题渲染为 caption,
+// 表头行渲染在 thead 中, 样式, 公式, 图片等不会渲染; 跳过的行见 WithContinueOnError, 此时仍然写入
+func WriteHTMLTable(w io.Writer, sheetModels []SheetModel, opts ...Option) error {
+	writer := bufio.NewWriter(w)
+	err := renderSheets(sheetModels, opts, func(_ string, layout *sheetLayout, rows [][]string) error {
+		writeHTMLSheet(writer, layout, rows)
+		return nil
+	})
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	if flushErr := writer.Flush(); flushErr != nil {
+		return flushErr
+	}
+	return err // rows skipped by WithContinueOnError
+}
+
+// renderSheets writes sheetModels and calls fn with the layout and the displayed text of the rows of each written sheet
+// in order, it returns RowErrors after all sheets are rendered if rows are skipped by WithContinueOnError
+func renderSheets(sheetModels []SheetModel, opts []Option, fn func(sheetName string, layout *sheetLayout, rows [][]string) error) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	builder, err := newBuilder(f, false, opts...)
+	if err != nil {
+		return err
+	}
+	builder.Append(sheetModels...)
+	if err = builder.finish(); err != nil && !isRowErrors(err) {
+		return err
+	}
+	for _, sheetName := range f.GetSheetList() {
+		layout, ok := builder.options.sheetLayouts[sheetName]
+		if !ok || len(layout.columns) == 0 {
+			continue
+		}
+		rows, getErr := f.GetRows(sheetName)
+		if getErr != nil {
+			return getErr
+		}
+		if fnErr := fn(sheetName, layout, rows); fnErr != nil {
+			return fnErr
+		}
+	}
+	return err
+}
+
+// cellText returns the text of the cell at col and row (start from 1) of layout in rows, it is empty if the cell is not set
+func (l *sheetLayout) cellText(rows [][]string, col, row int) string {
+	row, col = l.row(row)-1, l.col(col)-1
+	if row >= len(rows) || col >= len(rows[row]) {
+		return ""
+	}
+	return rows[row][col]
+}
+
+// writeHTMLSheet writes the rows of the sheet with layout as a table to w, errors are returned by w.Flush
+func writeHTMLSheet(w *bufio.Writer, layout *sheetLayout, rows [][]string) {
+	cell := func(row, col int) string {
+		return html.EscapeString(layout.cellText(rows, col, row))
+	}
+	_, _ = w.WriteString("<table>\n")
+	if layout.titleRows > 0 {
+		_, _ = w.WriteString("<caption>" + cell(1, 1) + "</caption>\n")
+	}
+	for row := layout.titleRows + 1; row <= layout.rows; row++ {
+		tag := "td"
+		switch row {
+		case layout.titleRows + 1:
+			if layout.headerRows > layout.titleRows {
+				_, _ = w.WriteString("<thead>\n")
+			} else {
+				_, _ = w.WriteString("<tbody>\n")
+			}
+		case layout.headerRows + 1:
+			_, _ = w.WriteString("</thead>\n<tbody>\n")
+		}
+		if row <= layout.headerRows {
+			tag = "th"
+		}
+		_, _ = w.WriteString("<tr>")
+		for col := 1; col <= len(layout.columns); col++ {
+			_, _ = w.WriteString("<" + tag + ">" + cell(row, col) + "</" + tag + ">")
+		}
+		_, _ = w.WriteString("</tr>\n")
+	}
+	switch {
+	case layout.rows > layout.headerRows:
+		_, _ = w.WriteString("</tbody>\n")
+	case layout.headerRows > layout.titleRows:
+		_, _ = w.WriteString("</thead>\n")
+	}
+	_, _ = w.WriteString("</table>\n")
+}
@@ -0,0 +1,24 @@
+package excelorm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTMLTable(t *testing.T) {
+	var buffer bytes.Buffer
+	models := []SheetModel{Sheet30{Name: "<foo>", Balance: 1.5}, Sheet30{Name: "bar"}}
+	require.NoError(t, WriteHTMLTable(&buffer, models, WithSheetTitle("sheet30", "Accounts", nil)))
+	assert.Equal(t, "<table>\n<caption>Accounts</caption>\n"+
+		"<thead>\n<tr><th>name</th><th>balance</th></tr>\n</thead>\n"+
+		"<tbody>\n<tr><td>&lt;foo&gt;</td><td>1.50</td></tr>\n<tr><td>bar</td><td>0.00</td></tr>\n</tbody>\n"+
+		"</table>\n", buffer.String())
+
+	buffer.Reset()
+	require.NoError(t, WriteHTMLTable(&buffer, models, WithHeadless(), WithTempFileThreshold(0)))
+	assert.Equal(t, "<table>\n<tbody>\n<tr><td>&lt;foo&gt;</td><td>1.50</td></tr>\n<tr><td>bar</td><td>0.00</td></tr>\n</tbody>\n"+
+		"</table>\n", buffer.String())
+}
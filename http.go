@@ -0,0 +1,61 @@
+package excelorm
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ContentTypeXLSX .xlsx 文件的 MIME 类型
+const ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// ServeExcel 生成 excel 并作为附件写入 w, 用法同 WriteExcelSaveAs, filename 为下载的文件名, 可以包含中文等非 ASCII 字符;
+// 设置 Content-Type 和 RFC 5987 格式的 Content-Disposition, 生成失败时不写入响应, 由调用方处理错误,
+// 跳过的行见 WithContinueOnError, 此时仍然写入响应
+func ServeExcel(w http.ResponseWriter, filename string, sheetModels []SheetModel, opts ...Option) error {
+	if filename == "" {
+		return ErrEmptyFileName
+	}
+	f, err := write(sheetModels, opts...)
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	defer f.Close() // remove temp files of stream writers
+	w.Header().Set("Content-Type", ContentTypeXLSX)
+	w.Header().Set("Content-Disposition", ContentDisposition(filename))
+	if _, writeErr := f.WriteTo(w); writeErr != nil {
+		return writeErr
+	}
+	return err // rows skipped by WithContinueOnError
+}
+
+// ContentDisposition 返回下载 filename 的 Content-Disposition 响应头, filename 为 ASCII 的兼容写法及 RFC 5987 编码的 filename*,
+// 如 报表.xlsx 的响应头为:
+//
+//	attachment; filename="__.xlsx"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.xlsx
+func ContentDisposition(filename string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	for i := 0; i < len(filename); i++ {
+		if c := filename[i]; isAttrChar(c) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return `attachment; filename="` + fallback.String() + `"; filename*=UTF-8''` + encoded.String()
+}
+
+// isAttrChar reports whether c is an attr-char of RFC 5987, which is not percent-encoded
+func isAttrChar(c byte) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,28 @@
+package excelorm
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestServeExcel(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := ServeExcel(recorder, "报表 2024.xlsx", []SheetModel{Sheet30{Name: "foo"}})
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeXLSX, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="__ 2024.xlsx"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8%202024.xlsx`,
+		recorder.Header().Get("Content-Disposition"))
+	f, err := excelize.OpenReader(recorder.Body)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "0.00"}}, getRows(t, f, "sheet30"))
+
+	recorder = httptest.NewRecorder()
+	err = ServeExcel(recorder, "foo.xlsx", []SheetModel{nil})
+	assert.ErrorIs(t, err, ErrNilRow)
+	assert.Empty(t, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="a_b_.xlsx"; filename*=UTF-8''a%22b%5C.xlsx`, ContentDisposition(`a"b\.xlsx`))
+}
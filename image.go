@@ -0,0 +1,159 @@
+package excelorm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Image 图片类型的字段, 图片锚定到该字段的单元格并随单元格移动, 单元格不写入文本, 用于商品目录等导出嵌入缩略图;
+// 按 Data, Path, URL 的顺序使用第一个不为空的来源, 都为空时展示为 WithIfNullValue 设置的空值, 不支持流式写入
+// example usage:
+//
+//	type Product struct {
+//		Name      string         `excel_header:"name"`
+//		Thumbnail excelorm.Image `excel_header:"thumbnail"`
+//	}
+//	product := Product{Name: "foo", Thumbnail: excelorm.Image{URL: "https://example.com/foo.png", AutoFit: true}}
+type Image struct {
+	Data      []byte  // 图片的内容
+	Extension string  // 图片的扩展名, 如 ".png", 为空时按 Path, URL 或 Data 的内容判断
+	Path      string  // 本地图片的路径
+	URL       string  // 图片的地址, 写入时下载, 见 WithImageFetcher
+	Scale     float64 // 缩放比例, 如 0.5, 为0时不缩放
+	AutoFit   bool    // 是否按单元格的大小缩放图片, 保持宽高比, 设置时忽略 Scale
+	AltText   string  // 图片的替代文本
+}
+
+// ImageFetcher 下载 Image.URL 的图片, 返回图片的内容和 Content-Type, 无法判断时 Content-Type 可以为空
+type ImageFetcher func(url string) (data []byte, contentType string, err error)
+
+// WithImageFetcher 设置下载 Image.URL 的函数, 如设置认证或缓存, 默认使用 GET 请求下载, 每张图片的超时时间为 30 秒,
+// 需要其他超时时间或取消导出时, 使用自定义的 http.Client 或 http.NewRequestWithContext 下载
+func WithImageFetcher(fetcher ImageFetcher) Option {
+	return func(options *options) {
+		options.imageFetcher = fetcher
+	}
+}
+
+var imageType = reflect.TypeOf(Image{})
+
+// imageExtensions are the extensions of the picture types detected by http.DetectContentType
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/bmp":  ".bmp",
+	"image/tiff": ".tiff",
+}
+
+// cellPicture is a picture anchored to the cell
+type cellPicture struct {
+	cell    string
+	picture *excelize.Picture
+}
+
+// isEmpty reports whether image has no source
+func (i Image) isEmpty() bool {
+	return len(i.Data) == 0 && i.Path == "" && i.URL == ""
+}
+
+// picture loads the content of image
+func (i Image) picture(options *options) (*excelize.Picture, error) {
+	data, extension, contentType := i.Data, i.Extension, ""
+	var err error
+	switch {
+	case len(data) > 0:
+	case i.Path != "":
+		if data, err = os.ReadFile(i.Path); err != nil {
+			return nil, err
+		}
+		if extension == "" {
+			extension = filepath.Ext(i.Path)
+		}
+	default:
+		fetcher := options.imageFetcher
+		if fetcher == nil {
+			fetcher = fetchImage
+		}
+		if data, contentType, err = fetcher(i.URL); err != nil {
+			return nil, err
+		}
+		if u, parseErr := url.Parse(i.URL); parseErr == nil && extension == "" {
+			extension = path.Ext(u.Path)
+		}
+	}
+	if extension == "" && contentType != "" {
+		if mediaType, _, parseErr := mime.ParseMediaType(contentType); parseErr == nil {
+			extension = imageExtensions[mediaType]
+		}
+	}
+	if extension == "" {
+		extension = imageExtensions[http.DetectContentType(data)]
+	}
+	if extension == "" {
+		return nil, errors.New("unknown image format")
+	}
+	format := &excelize.GraphicOptions{AltText: i.AltText, Positioning: "oneCell", LockAspectRatio: true}
+	if i.AutoFit {
+		format.AutoFit = true
+	} else if i.Scale > 0 {
+		format.ScaleX, format.ScaleY = i.Scale, i.Scale
+	}
+	return &excelize.Picture{Extension: strings.ToLower(extension), File: data, Format: format}, nil
+}
+
+// imageClient is the client of fetchImage, the timeout prevents a slow image from hanging the export
+var imageClient = &http.Client{Timeout: 30 * time.Second}
+
+// fetchImage is the default ImageFetcher
+func fetchImage(url string) ([]byte, string, error) {
+	resp, err := imageClient.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("get %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	return data, resp.Header.Get("Content-Type"), err
+}
+
+// rowPictures loads the images in values of the row at line of layout, their cells are left empty
+func rowPictures(sheetName string, layout *sheetLayout, line int, columns []column, values []interface{},
+	options *options) ([]cellPicture, error) {
+	var pictures []cellPicture
+	for i, value := range values {
+		image, ok := value.(Image)
+		if !ok {
+			continue
+		}
+		values[i] = ""
+		err := errors.New("images are not supported by stream writers")
+		var picture *excelize.Picture
+		if !options.streaming {
+			picture, err = image.picture(options)
+		}
+		if err != nil {
+			return nil, &CellError{Sheet: sheetName, Row: layout.row(line), Column: columns[i].header, Field: columns[i].field.Name, Err: err}
+		}
+		cellName, err := layout.cellName(i+1, line)
+		if err != nil {
+			return nil, err
+		}
+		pictures = append(pictures, cellPicture{cell: cellName, picture: picture})
+	}
+	return pictures, nil
+}
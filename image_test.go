@@ -0,0 +1,87 @@
+package excelorm
+
+import (
+	"bytes"
+	"image"
+	imagecolor "image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Sheet41 struct {
+	Name      string `excel_header:"name"`
+	Thumbnail Image  `excel_header:"thumbnail"`
+	Photo     *Image `excel_header:"photo"`
+}
+
+func (Sheet41) SheetName() string {
+	return "sheet41"
+}
+
+func TestImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, imagecolor.RGBA{R: 255, A: 255})
+	var buffer bytes.Buffer
+	require.NoError(t, png.Encode(&buffer, img))
+	path := filepath.Join(t.TempDir(), "foo.png")
+	require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0o644))
+	var fetched []string
+	fetcher := func(url string) ([]byte, string, error) {
+		fetched = append(fetched, url)
+		return buffer.Bytes(), "image/png", nil
+	}
+
+	models := []SheetModel{
+		Sheet41{Name: "foo", Thumbnail: Image{Data: buffer.Bytes(), AutoFit: true}, Photo: &Image{Path: path}},
+		Sheet41{Name: "bar", Thumbnail: Image{URL: "https://example.com/bar?size=small", Scale: 0.5}},
+	}
+	f, err := write(models, WithImageFetcher(fetcher), WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "thumbnail", "photo"}, {"foo"}, {"bar", "", "-"}}, getRows(t, f, "sheet41"))
+	assert.Equal(t, []string{"https://example.com/bar?size=small"}, fetched)
+	for _, cell := range []string{"B2", "C2", "B3"} {
+		pictures, err := f.GetPictures("sheet41", cell)
+		require.NoError(t, err)
+		require.Len(t, pictures, 1, cell)
+		assert.Equal(t, ".png", pictures[0].Extension)
+		assert.Equal(t, buffer.Bytes(), pictures[0].File)
+	}
+
+	var cellErr *CellError
+	_, err = write(models[:1], WithTempFileThreshold(0))
+	require.ErrorAs(t, err, &cellErr)
+	assert.Equal(t, "thumbnail", cellErr.Column)
+	_, err = write([]SheetModel{Sheet41{Thumbnail: Image{Data: []byte("foo")}}})
+	assert.ErrorAs(t, err, &cellErr)
+}
+
+func TestFetchImage(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.png" {
+			<-done
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("foo"))
+	}))
+	defer server.Close()
+	defer close(done)
+	client := imageClient
+	imageClient = &http.Client{Timeout: 100 * time.Millisecond}
+	defer func() { imageClient = client }()
+
+	data, contentType, err := fetchImage(server.URL + "/foo.png")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foo"), data)
+	assert.Equal(t, "image/png", contentType)
+	_, _, err = fetchImage(server.URL + "/slow.png")
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+}
@@ -0,0 +1,102 @@
+package excelorm
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON 按 sheetModels 和 opts 生成 excel 并将展示的内容以 JSON 写入 w, 使 API 的调用方看到与 excel 完全相同的内容;
+// 结果为 sheet 名称到数据行的对象, 按 sheet 的顺序排列, 每行为表头到单元格文本的对象, 按列的顺序排列,
+// 单元格文本为格式化后的时间, WithIfNullValue 设置的空值, WithBoolValueAs 设置的文本等, 如:
+//
+//	{"orders":[{"id":"1","created at":"2024-01-01 00:00:00"}]}
+//
+// 跳过的行见 WithContinueOnError, 此时仍然写入
+func WriteJSON(w io.Writer, sheetModels []SheetModel, opts ...Option) error {
+	writer := bufio.NewWriter(w)
+	_ = writer.WriteByte('{')
+	sheets := 0
+	err := renderSheets(sheetModels, opts, func(sheetName string, layout *sheetLayout, rows [][]string) error {
+		if sheets++; sheets > 1 {
+			_ = writer.WriteByte(',')
+		}
+		if err := writeJSONString(writer, sheetName); err != nil {
+			return err
+		}
+		_, _ = writer.WriteString(":[")
+		for row := layout.headerRows + 1; row <= layout.rows; row++ {
+			if row > layout.headerRows+1 {
+				_ = writer.WriteByte(',')
+			}
+			if err := writeJSONRow(writer, layout, rows, row); err != nil {
+				return err
+			}
+		}
+		return writer.WriteByte(']')
+	})
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	_, _ = writer.WriteString("}\n")
+	if flushErr := writer.Flush(); flushErr != nil {
+		return flushErr
+	}
+	return err // rows skipped by WithContinueOnError
+}
+
+// WriteNDJSON 同 WriteJSON, 但每个数据行为单独的一行 JSON(ND-JSON), 便于流式处理, 每行包括 sheet 名称和数据行, 如:
+//
+//	{"sheet":"orders","row":{"id":"1","created at":"2024-01-01 00:00:00"}}
+func WriteNDJSON(w io.Writer, sheetModels []SheetModel, opts ...Option) error {
+	writer := bufio.NewWriter(w)
+	err := renderSheets(sheetModels, opts, func(sheetName string, layout *sheetLayout, rows [][]string) error {
+		for row := layout.headerRows + 1; row <= layout.rows; row++ {
+			_, _ = writer.WriteString(`{"sheet":`)
+			if err := writeJSONString(writer, sheetName); err != nil {
+				return err
+			}
+			_, _ = writer.WriteString(`,"row":`)
+			if err := writeJSONRow(writer, layout, rows, row); err != nil {
+				return err
+			}
+			_, _ = writer.WriteString("}\n")
+		}
+		return nil
+	})
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	if flushErr := writer.Flush(); flushErr != nil {
+		return flushErr
+	}
+	return err // rows skipped by WithContinueOnError
+}
+
+// writeJSONRow writes the row (start from 1) of layout in rows as an object keyed by headers to w
+func writeJSONRow(w *bufio.Writer, layout *sheetLayout, rows [][]string, row int) error {
+	_ = w.WriteByte('{')
+	for col, column := range layout.columns {
+		if col > 0 {
+			_ = w.WriteByte(',')
+		}
+		if err := writeJSONString(w, column.header); err != nil {
+			return err
+		}
+		_ = w.WriteByte(':')
+		if err := writeJSONString(w, layout.cellText(rows, col+1, row)); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// writeJSONString writes s as a JSON string to w
+func writeJSONString(w *bufio.Writer, s string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
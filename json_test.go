@@ -0,0 +1,33 @@
+package excelorm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Sheet40 struct {
+	Name   string  `excel_header:"name"`
+	Active bool    `excel_header:"active"`
+	Note   *string `excel_header:"note"`
+}
+
+func (Sheet40) SheetName() string {
+	return "sheet40"
+}
+
+func TestWriteJSON(t *testing.T) {
+	models := []SheetModel{Sheet40{Name: "foo", Active: true}, Sheet30{Name: "bar"}}
+	opts := []Option{WithBoolValueAs("yes", "no"), WithIfNullValue("-")}
+	var buffer bytes.Buffer
+	require.NoError(t, WriteJSON(&buffer, models, opts...))
+	assert.Equal(t, `{"sheet40":[{"name":"foo","active":"yes","note":"-"}],"sheet30":[{"name":"bar","balance":"0.00"}]}`+"\n",
+		buffer.String())
+
+	buffer.Reset()
+	require.NoError(t, WriteNDJSON(&buffer, models, opts...))
+	assert.Equal(t, `{"sheet":"sheet40","row":{"name":"foo","active":"yes","note":"-"}}`+"\n"+
+		`{"sheet":"sheet30","row":{"name":"bar","balance":"0.00"}}`+"\n", buffer.String())
+}
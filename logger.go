@@ -0,0 +1,29 @@
+package excelorm
+
+import "fmt"
+
+// Logger 输出警告的日志接口, *log.Logger 实现了该接口, *slog.Logger 可以通过 slog.NewLogLogger 转换
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithLogger 通过 logger 输出不影响写入的警告, 如 sheet 名称不合法被替换, 单元格内容超过32767个字符被截断,
+// 同一个 sheet 写入了不同类型的数据, 相同的警告只输出一次
+func WithLogger(logger Logger) Option {
+	return func(options *options) {
+		options.logger = logger
+	}
+}
+
+// warn prints the warning by options.logger if it is not printed before
+func (o *options) warn(format string, v ...interface{}) {
+	if o.logger == nil {
+		return
+	}
+	message := fmt.Sprintf(format, v...)
+	if o.warnings[message] {
+		return
+	}
+	o.warnings[message] = true
+	o.logger.Printf("excelorm: %s", message)
+}
@@ -0,0 +1,29 @@
+package excelorm
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	models := []SheetModel{
+		Sheet34{Sheet: "a/b", Name: "foo"},
+		Sheet34{Sheet: "a/b", Name: strings.Repeat("x", 32768)},
+		Sheet34{Sheet: "sheet30", Name: "bar"},
+		Sheet30{Name: "baz"},
+		Sheet30{Name: "qux"},
+	}
+	buffer := new(bytes.Buffer)
+	_, err := write(models, WithLogger(log.New(buffer, "", 0)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"excelorm: sheet name a/b is invalid, it is replaced by a_b",
+		"excelorm: value of cell A3 in sheet a_b is truncated to 32767 characters",
+		"excelorm: sheet sheet30 has rows of both excelorm.Sheet34 and excelorm.Sheet30",
+	}, strings.Split(strings.TrimSpace(buffer.String()), "\n"))
+}
@@ -0,0 +1,86 @@
+package excelorm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// mimeLineLength is the maximum length of the lines of base64 encoded bodies, see RFC 2045
+const mimeLineLength = 76
+
+// MIMEAttachment 邮件附件, 用于 net/smtp 拼接的 multipart 邮件或 gomail, go-mail 等邮件库
+type MIMEAttachment struct {
+	Filename    string               // 附件的文件名
+	ContentType string               // 附件的 MIME 类型, 即 ContentTypeXLSX
+	Header      textproto.MIMEHeader // 附件的 Content-Type, Content-Disposition 和 Content-Transfer-Encoding
+	Data        []byte               // excel 文件的原始内容, 用于自行编码的邮件库, 如 gomail 的 Attach
+	Body        []byte               // Data 的 base64 编码, 每行76个字符, 以 CRLF 换行
+}
+
+// AsMIMEAttachment 生成 excel 并作为名为 filename 的邮件附件返回, 用法同 WriteExcelSaveAs, filename 可以包含中文等非 ASCII 字符,
+// 按 RFC 2231 编码; 跳过的行见 WithContinueOnError, 此时同时返回附件和 RowErrors
+// example usage:
+//
+//	attachment, err := excelorm.AsMIMEAttachment("报表.xlsx", sheetModels)
+//	if err != nil {
+//		return err
+//	}
+//	writer := multipart.NewWriter(&message)
+//	// write the text part of the mail
+//	if err = attachment.WritePart(writer); err != nil {
+//		return err
+//	}
+func AsMIMEAttachment(filename string, sheetModels []SheetModel, opts ...Option) (*MIMEAttachment, error) {
+	if filename == "" {
+		return nil, ErrEmptyFileName
+	}
+	f, err := write(sheetModels, opts...)
+	if err != nil && !isRowErrors(err) {
+		return nil, err
+	}
+	defer f.Close() // remove temp files of stream writers
+	buffer, writeErr := f.WriteToBuffer()
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	data := buffer.Bytes()
+	attachment := &MIMEAttachment{
+		Filename:    filename,
+		ContentType: ContentTypeXLSX,
+		Header:      make(textproto.MIMEHeader),
+		Data:        data,
+		Body:        encodeMIMEBody(data),
+	}
+	attachment.Header.Set("Content-Type", mime.FormatMediaType(ContentTypeXLSX, map[string]string{"name": filename}))
+	attachment.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	attachment.Header.Set("Content-Transfer-Encoding", "base64")
+	return attachment, err // rows skipped by WithContinueOnError
+}
+
+// WritePart 将附件作为 w 的一个部分写入
+func (a *MIMEAttachment) WritePart(w *multipart.Writer) error {
+	part, err := w.CreatePart(a.Header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(a.Body)
+	return err
+}
+
+// encodeMIMEBody encodes data to base64 lines of mimeLineLength characters
+func encodeMIMEBody(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var body bytes.Buffer
+	body.Grow(len(encoded) + len(encoded)/mimeLineLength*2 + 2)
+	for len(encoded) > mimeLineLength {
+		body.WriteString(encoded[:mimeLineLength])
+		body.WriteString("\r\n")
+		encoded = encoded[mimeLineLength:]
+	}
+	body.WriteString(encoded)
+	body.WriteString("\r\n")
+	return body.Bytes()
+}
@@ -0,0 +1,46 @@
+package excelorm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestAsMIMEAttachment(t *testing.T) {
+	attachment, err := AsMIMEAttachment("报表.xlsx", []SheetModel{Sheet30{Name: "foo"}})
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeXLSX, attachment.ContentType)
+	assert.Equal(t, "base64", attachment.Header.Get("Content-Transfer-Encoding"))
+	_, params, err := mime.ParseMediaType(attachment.Header.Get("Content-Disposition"))
+	require.NoError(t, err)
+	assert.Equal(t, "报表.xlsx", params["filename"])
+
+	var message bytes.Buffer
+	writer := multipart.NewWriter(&message)
+	require.NoError(t, attachment.WritePart(writer))
+	require.NoError(t, writer.Close())
+	part, err := multipart.NewReader(&message, writer.Boundary()).NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "报表.xlsx", part.FileName())
+	body, err := io.ReadAll(part)
+	require.NoError(t, err)
+	for _, line := range bytes.Split(bytes.TrimSpace(body), []byte("\r\n")) {
+		assert.LessOrEqual(t, len(line), 76)
+	}
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(bytes.ReplaceAll(body, []byte("\r\n"), nil))))
+	require.NoError(t, err)
+	assert.Equal(t, attachment.Data, data)
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "0.00"}}, getRows(t, f, "sheet30"))
+
+	_, err = AsMIMEAttachment("", nil)
+	assert.ErrorIs(t, err, ErrEmptyFileName)
+}
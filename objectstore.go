@@ -0,0 +1,39 @@
+package excelorm
+
+import (
+	"context"
+	"io"
+)
+
+// Uploader 对象存储的上传接口, 如 S3, OSS, GCS 客户端的简单封装, Upload 从 body 读取 key 的全部内容,
+// body 的长度未知, 需要客户端支持分片或流式上传, 如 s3manager.Uploader
+type Uploader interface {
+	Upload(ctx context.Context, key string, body io.Reader) error
+}
+
+// WriteExcelToObjectStore 生成 excel 并通过 uploader 上传为 key, 用法同 WriteExcelSaveAs, excel 边写入边上传,
+// 不经过中间的 bytes.Buffer 或本地文件; 跳过的行见 WithContinueOnError, 此时仍然上传
+func WriteExcelToObjectStore(ctx context.Context, uploader Uploader, key string, sheetModels []SheetModel, opts ...Option) error {
+	f, err := write(sheetModels, opts...)
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	defer f.Close() // remove temp files of stream writers
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, writeErr := f.WriteTo(writer)
+		_ = writer.CloseWithError(writeErr) // the reader gets io.EOF if writeErr is nil
+	}()
+	uploadErr := uploader.Upload(ctx, key, reader)
+	_ = reader.CloseWithError(io.ErrClosedPipe) // stop writing if the uploader returns before reading all
+	<-done
+	if uploadErr != nil {
+		return uploadErr
+	}
+	return err // rows skipped by WithContinueOnError
+}
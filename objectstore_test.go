@@ -0,0 +1,48 @@
+package excelorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+type memoryUploader struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (u *memoryUploader) Upload(ctx context.Context, key string, body io.Reader) error {
+	if u.err != nil {
+		return u.err // return without reading body
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	u.objects[key] = data
+	return nil
+}
+
+func TestWriteExcelToObjectStore(t *testing.T) {
+	uploader := &memoryUploader{objects: make(map[string][]byte)}
+	err := WriteExcelToObjectStore(context.Background(), uploader, "reports/accounts.xlsx", []SheetModel{Sheet30{Name: "foo"}})
+	require.NoError(t, err)
+	f, err := excelize.OpenReader(bytes.NewReader(uploader.objects["reports/accounts.xlsx"]))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "0.00"}}, getRows(t, f, "sheet30"))
+
+	uploader.err = errors.New("access denied")
+	err = WriteExcelToObjectStore(context.Background(), uploader, "foo.xlsx", []SheetModel{Sheet30{Name: "foo"}})
+	assert.EqualError(t, err, "access denied")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = WriteExcelToObjectStore(ctx, uploader, "foo.xlsx", []SheetModel{Sheet30{Name: "foo"}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,141 @@
+package excelorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// queryBatchSize is the number of rows of ExportQuery written by each Builder.Flush
+const queryBatchSize = 1000
+
+// sqlDecimal is a DECIMAL or NUMERIC value, it is kept as text to avoid precision loss
+type sqlDecimal string
+
+func (d *sqlDecimal) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		*d = sqlDecimal(src)
+	case string:
+		*d = sqlDecimal(src)
+	case int64:
+		*d = sqlDecimal(strconv.FormatInt(src, 10))
+	case float64:
+		*d = sqlDecimal(strconv.FormatFloat(src, 'f', -1, 64))
+	default:
+		return fmt.Errorf("can not scan %T into decimal", src)
+	}
+	return nil
+}
+
+func (d sqlDecimal) MarshalText() ([]byte, error) {
+	return []byte(d), nil
+}
+
+// StringFixed rounds d to places decimal places, see WithDecimalPlaces
+func (d sqlDecimal) StringFixed(places int32) string {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return string(d)
+	}
+	return r.FloatString(int(places))
+}
+
+var sqlDecimalType = reflect.TypeOf(sqlDecimal(""))
+
+// ExportQuery 执行 query 并将结果写入名为 sheetName 的 sheet, 表头为查询的列名, 用法同 WriteExcelSaveAs,
+// 按列的类型写入单元格: NULL 为 WithIfNullValue 设置的空值, 时间按 WithTimeFormatLayout 或 WithTimeAsNativeExcelDate 展示,
+// DECIMAL, NUMERIC 按文本保留全部精度(可以通过 WithDecimalPlaces 指定小数位数), 整数和小数同结构体的字段;
+// 每1000行写入一次, 与 WithTempFileThreshold(0) 同时使用时内存占用不随行数增长, 返回的 Builder 中数据已全部写入, 由调用方保存并关闭
+// example usage:
+//
+//	builder, err := excelorm.ExportQuery(ctx, db, "SELECT id, name, amount FROM orders WHERE status = ?", []interface{}{1}, "orders")
+//	if err != nil {
+//		return err
+//	}
+//	defer builder.Close()
+//	return builder.SaveAs("orders.xlsx")
+func ExportQuery(ctx context.Context, db *sql.DB, query string, args []interface{}, sheetName string,
+	opts ...Option) (*Builder, error) {
+	if sheetName == "" {
+		return nil, ErrEmptySheetName
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	rowType, err := queryRowType(columnTypes)
+	if err != nil {
+		return nil, err
+	}
+	builder, err := NewBuilder(opts...)
+	if err != nil {
+		return nil, err
+	}
+	exportErr := func() error {
+		dest := make([]interface{}, rowType.NumField())
+		for n := 1; rows.Next(); n++ {
+			value := reflect.New(rowType).Elem() // each row has its own value, rows are written when they are flushed
+			for i := range dest {
+				dest[i] = value.Field(i).Addr().Interface()
+			}
+			if err := rows.Scan(dest...); err != nil {
+				return err
+			}
+			builder.Append(structRow{sheet: sheetName, value: value})
+			if n%queryBatchSize == 0 {
+				if err := builder.Flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return builder.Flush()
+	}()
+	if exportErr != nil {
+		_ = builder.Close()
+		return nil, exportErr
+	}
+	return builder, nil
+}
+
+// queryRowType returns a struct type with a pointer field for each column, the header of the field is the column name,
+// pointers are nil if the columns are NULL
+func queryRowType(columnTypes []*sql.ColumnType) (reflect.Type, error) {
+	fields := make([]reflect.StructField, len(columnTypes))
+	for i, columnType := range columnTypes {
+		if columnType.Name() == "" || columnType.Name() == "-" {
+			return nil, fmt.Errorf("column %d has invalid name %q", i+1, columnType.Name())
+		}
+		scanType := columnType.ScanType()
+		switch databaseType := strings.ToUpper(columnType.DatabaseTypeName()); {
+		case databaseType == "DECIMAL" || databaseType == "NUMERIC":
+			scanType = sqlDecimalType
+		case scanType == nil || scanType.Kind() == reflect.Pointer:
+			scanType = reflect.TypeOf((*interface{})(nil)).Elem()
+		case scanType == reflect.TypeOf(sql.RawBytes{}):
+			scanType = reflect.TypeOf([]byte{}) // raw bytes are reused by the next row
+		}
+		fields[i] = reflect.StructField{
+			Name: "Column" + strconv.Itoa(i+1),
+			Type: reflect.PointerTo(scanType),
+			Tag:  reflect.StructTag("excel_header:" + strconv.Quote(columnType.Name())),
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("query returns no columns")
+	}
+	return reflect.StructOf(fields), nil
+}
@@ -0,0 +1,93 @@
+package excelorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver returns the same rows for any query
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeRows struct {
+	index int
+}
+
+var (
+	fakeColumns  = []string{"id", "name", "amount", "created_at"}
+	fakeDBTypes  = []string{"BIGINT", "VARCHAR", "DECIMAL", "TIMESTAMP"}
+	fakeScanType = []reflect.Type{reflect.TypeOf(int64(0)), reflect.TypeOf(""), reflect.TypeOf([]byte{}), reflect.TypeOf(time.Time{})}
+	fakeRowData  = [][]driver.Value{
+		{int64(1), "foo", []byte("12345678901234567.891"), time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{int64(2), nil, nil, nil},
+	}
+)
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+func (*fakeRows) Columns() []string { return fakeColumns }
+
+func (*fakeRows) Close() error { return nil }
+
+func (*fakeRows) ColumnTypeDatabaseTypeName(index int) string { return fakeDBTypes[index] }
+
+func (*fakeRows) ColumnTypeScanType(index int) reflect.Type { return fakeScanType[index] }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index == len(fakeRowData) {
+		return io.EOF
+	}
+	copy(dest, fakeRowData[r.index])
+	r.index++
+	return nil
+}
+
+func init() {
+	sql.Register("excelorm-fake", fakeDriver{})
+}
+
+func TestExportQuery(t *testing.T) {
+	db, err := sql.Open("excelorm-fake", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	builder, err := ExportQuery(context.Background(), db, "SELECT * FROM orders", nil, "orders", WithIfNullValue("-"))
+	require.NoError(t, err)
+	defer builder.Close()
+	require.NoError(t, builder.finishOnce())
+	assert.Equal(t, [][]string{
+		{"id", "name", "amount", "created_at"},
+		{"1", "foo", "12345678901234567.891", "2024-01-02 15:04:05"},
+		{"2", "-", "-", "-"},
+	}, getRows(t, builder.f, "orders"))
+
+	builder, err = ExportQuery(context.Background(), db, "SELECT * FROM orders", nil, "orders",
+		WithDecimalPlaces(2), WithTimeAsNativeExcelDate(""))
+	require.NoError(t, err)
+	defer builder.Close()
+	require.NoError(t, builder.finishOnce())
+	assert.Equal(t, "12345678901234567.89", getCellValue(t, builder.f, "orders", "C2"))
+	assert.Equal(t, "2024-01-02 15:04:05", getCellValue(t, builder.f, "orders", "D2"))
+
+	_, err = ExportQuery(context.Background(), db, "SELECT * FROM orders", nil, "")
+	assert.ErrorIs(t, err, ErrEmptySheetName)
+}
@@ -0,0 +1,219 @@
+package excelorm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithDeduplicateBy 丢弃 sheet 中表头为 headers 的列的值与之前的行都相同的行, 只保留第一行, 用于合并多个数据源的导出,
+// 按单元格中展示的内容比较, 丢弃的行通过 WithLogger 输出警告; 拆分后的 sheet (见 WithAutoSplitSheets) 与原 sheet 一起比较
+func WithDeduplicateBy(sheet string, headers ...string) Option {
+	return func(options *options) {
+		if options.deduplicateBy == nil {
+			options.deduplicateBy = make(map[string][]string)
+		}
+		options.deduplicateBy[sanitizeSheetName(sheet)] = headers
+	}
+}
+
+// isDuplicateRow reports whether values of columns of the row at line of layout repeat the keys of a previous row
+// of sheet, and returns the keys to record by recordRowKey after the row is written,
+// sheet is the name before WithAutoSplitSheets
+func isDuplicateRow(sheet string, layout *sheetLayout, line int, columns []column, values []interface{},
+	options *options) (string, bool, error) {
+	headers := options.deduplicateBy[sheet]
+	if len(headers) == 0 {
+		return "", false, nil
+	}
+	var key strings.Builder
+	for _, header := range headers {
+		i := 0
+		for i < len(columns) && columns[i].header != header {
+			i++
+		}
+		if i == len(columns) {
+			return "", false, fmt.Errorf("column %s not found in sheet %s", header, sheet)
+		}
+		fmt.Fprintf(&key, "%v\x00", values[i])
+	}
+	if options.deduplicateKeys[sheet][key.String()] {
+		options.warn("row %d of sheet %s is dropped, its %s duplicate a previous row", layout.row(line), sheet,
+			strings.Join(headers, ", "))
+		return "", true, nil
+	}
+	return key.String(), false, nil
+}
+
+// recordRowKey records key returned by isDuplicateRow of a row written to sheet, a row skipped by WithContinueOnError
+// is not recorded, so a later row with the same keys is still written
+func recordRowKey(sheet, key string, options *options) {
+	if key == "" {
+		return
+	}
+	keys := options.deduplicateKeys[sheet]
+	if keys == nil {
+		keys = make(map[string]bool)
+		options.deduplicateKeys[sheet] = keys
+	}
+	keys[key] = true
+}
+
+// sortKey is a key set by WithSortBy
+type sortKey struct {
+	header string
+	desc   bool
+}
+
+// sortedModel is a model of a sheet sorted by WithSortBy, index is its position in the models
+type sortedModel struct {
+	model SheetModel
+	sheet string
+	index int
+	keys  []reflect.Value // values of the sort keys, set by sortModels
+}
+
+// WithSortBy 按表头为 header 的列排序 sheet 的数据行, desc 为 true 时降序, 多次调用时依次作为排序的第一, 第二...关键字,
+// 排序是稳定的, 关键字都相同的行保持追加的顺序; 按字段的值比较, 数字按大小, 时间按先后, 字符串按字节序, 值为 nil 的行排在最后;
+// 排序的 sheet 在第一行数据追加时创建, sheet 的顺序不变, 数据在生成 excel 时才写入, 使用 WithTempFileThreshold 时这些数据仍然全部保存在内存中
+func WithSortBy(sheet, header string, desc bool) Option {
+	return func(options *options) {
+		if options.sortKeys == nil {
+			options.sortKeys = make(map[string][]sortKey)
+		}
+		sheet = sanitizeSheetName(sheet)
+		options.sortKeys[sheet] = append(options.sortKeys[sheet], sortKey{header: header, desc: desc})
+	}
+}
+
+// sortModels sorts models by the keys of their sheets stably
+func sortModels(models []sortedModel, options *options) error {
+	ranks := make(map[string]int) // rows are grouped by sheets in order of their first rows
+	for i, model := range models {
+		if _, ok := ranks[model.sheet]; !ok {
+			ranks[model.sheet] = len(ranks)
+		}
+		modelValue := valueOfModel(model.model)
+		if modelValue.Kind() != reflect.Struct {
+			continue // rejected when it is written
+		}
+		columns, err := parseColumns(modelValue.Type(), modelOptions(model.sheet, model.model, options))
+		if err != nil {
+			return err
+		}
+		for _, key := range options.sortKeys[model.sheet] {
+			col := 0
+			for col < len(columns) && columns[col].header != key.header {
+				col++
+			}
+			if col == len(columns) {
+				return fmt.Errorf("column %s not found in sheet %s", key.header, model.sheet)
+			}
+			value, _ := fieldByIndex(modelValue, columns[col].index)
+			models[i].keys = append(models[i].keys, value)
+		}
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		if models[i].sheet != models[j].sheet {
+			return ranks[models[i].sheet] < ranks[models[j].sheet]
+		}
+		for k, key := range options.sortKeys[models[i].sheet] {
+			if k >= len(models[i].keys) || k >= len(models[j].keys) {
+				return false
+			}
+			c := compareValues(models[i].keys[k], models[j].keys[k])
+			if c == 0 {
+				continue
+			}
+			if c == 2 || c == -2 { // one of them is nil
+				return c < 0
+			}
+			return (c < 0) != key.desc
+		}
+		return false
+	})
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareValues returns -1, 0 or 1 if a is less than, equal to or greater than b,
+// or -2 if only b is nil and 2 if only a is nil
+func compareValues(a, b reflect.Value) int {
+	for a.Kind() == reflect.Pointer || a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Pointer || b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0
+	case !a.IsValid():
+		return 2
+	case !b.IsValid():
+		return -2
+	}
+	if a.Type() == timeType && b.Type() == timeType && a.CanInterface() && b.CanInterface() {
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		return compareOrdered(ta.Before(tb), ta.After(tb))
+	}
+	switch ka, kb := numberKind(a.Kind()), numberKind(b.Kind()); {
+	case ka == 'i' && kb == 'i':
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int())
+	case ka == 'u' && kb == 'u':
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case ka != 0 && kb != 0:
+		fa, fb := numberAsFloat(a), numberAsFloat(b)
+		return compareOrdered(fa < fb, fa > fb)
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return compareOrdered(!a.Bool() && b.Bool(), a.Bool() && !b.Bool())
+	}
+	var sa, sb string
+	if a.CanInterface() {
+		sa = fmt.Sprint(a.Interface())
+	}
+	if b.CanInterface() {
+		sb = fmt.Sprint(b.Interface())
+	}
+	return strings.Compare(sa, sb)
+}
+
+// numberKind returns 'i', 'u' or 'f' if kind is a signed integer, unsigned integer or float, or 0 otherwise
+func numberKind(kind reflect.Kind) byte {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 'i'
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return 'u'
+	case reflect.Float32, reflect.Float64:
+		return 'f'
+	}
+	return 0
+}
+
+// numberAsFloat converts the number v to float64
+func numberAsFloat(v reflect.Value) float64 {
+	switch numberKind(v.Kind()) {
+	case 'i':
+		return float64(v.Int())
+	case 'u':
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// compareOrdered returns the result of compareValues by less and greater
+func compareOrdered(less, greater bool) int {
+	if less {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package excelorm
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeduplicateBy(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet30{Name: "bar", Balance: 1},
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet30{Name: "foo", Balance: 2},
+		Sheet1{Col1: "foo"},
+		Sheet1{Col1: "foo"},
+	}
+	buffer := new(bytes.Buffer)
+	f, err := write(models, WithDeduplicateBy("sheet30", "name", "balance"), WithLogger(log.New(buffer, "", 0)))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "1.00"}, {"bar", "1.00"}, {"foo", "2.00"}}, getRows(t, f, "sheet30"))
+	assert.Len(t, getRows(t, f, "sheet1"), 3)
+	assert.Equal(t, "excelorm: row 4 of sheet sheet30 is dropped, its name, balance duplicate a previous row\n", buffer.String())
+
+	f, err = write(models[:4], WithDeduplicateBy("sheet30", "name"), WithAutoSplitSheets(), withMaxRows(2))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "1.00"}}, getRows(t, f, "sheet30"))
+	assert.Equal(t, [][]string{{"name", "balance"}, {"bar", "1.00"}}, getRows(t, f, "sheet30 (2)"))
+
+	_, err = write(models, WithDeduplicateBy("sheet30", "id"))
+	require.EqualError(t, err, "column id not found in sheet sheet30")
+
+	// the keys of a skipped row are not recorded
+	images := []SheetModel{
+		Sheet41{Name: "foo", Thumbnail: Image{Path: filepath.Join(t.TempDir(), "missing.png")}},
+		Sheet41{Name: "foo"},
+		Sheet41{Name: "foo"},
+	}
+	f, err = write(images, WithDeduplicateBy("sheet41", "name"), WithContinueOnError())
+	var rowErrors RowErrors
+	require.ErrorAs(t, err, &rowErrors)
+	require.Len(t, rowErrors, 1)
+	assert.Equal(t, 0, rowErrors[0].Index)
+	assert.Equal(t, [][]string{{"name", "thumbnail", "photo"}, {"foo"}}, getRows(t, f, "sheet41"))
+}
+
+func TestWithSortBy(t *testing.T) {
+	foo, bar := "foo", "bar"
+	models := []SheetModel{
+		Sheet30{Name: "b", Balance: 1},
+		Sheet1{Col1: "x"},
+		Sheet30{Name: "c", Balance: 2},
+		Sheet1{Col1: "y", Col6: &foo},
+		Sheet30{Name: "a", Balance: 1},
+		Sheet1{Col1: "z", Col6: &bar},
+		Sheet30{Name: "d", Balance: 1},
+	}
+	var indexes []int
+	f, err := write(models,
+		WithSortBy("sheet30", "balance", true), WithSortBy("sheet30", "name", false), WithSortBy("sheet1", "string pointer", false),
+		WithAfterRowHook(func(model SheetModel, index int) error {
+			indexes = append(indexes, index)
+			return nil
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"c", "2.00"}, {"a", "1.00"}, {"b", "1.00"}, {"d", "1.00"}},
+		getRows(t, f, "sheet30"))
+	assert.Equal(t, "z", getCellValue(t, f, "sheet1", "A2"))
+	assert.Equal(t, "y", getCellValue(t, f, "sheet1", "A3"))
+	assert.Equal(t, "x", getCellValue(t, f, "sheet1", "A4")) // nil is the last
+	assert.Equal(t, []int{2, 4, 0, 6, 5, 3, 1}, indexes)
+
+	f, err = write(models, WithSortBy("sheet30", "balance", false))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"b", "1.00"}, {"a", "1.00"}, {"d", "1.00"}, {"c", "2.00"}},
+		getRows(t, f, "sheet30")) // stable
+	assert.Equal(t, "x", getCellValue(t, f, "sheet1", "A2"))
+
+	// sorted sheets keep their positions
+	mixed := []SheetModel{Sheet37{Name: "foo"}, Sheet30{Name: "b"}, Sheet40{Name: "bar"}, Sheet30{Name: "a"}}
+	for _, threshold := range []int64{-1, 0} {
+		f, err = write(mixed, WithSortBy("sheet30", "name", false), WithTempFileThreshold(threshold))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sheet37", "sheet30", "sheet40"}, f.GetSheetList())
+		assert.Equal(t, [][]string{{"name", "balance"}, {"a", "0.00"}, {"b", "0.00"}}, getRows(t, f, "sheet30"))
+	}
+
+	_, err = write(models, WithSortBy("sheet30", "id", false))
+	require.EqualError(t, err, "column id not found in sheet sheet30")
+}
@@ -0,0 +1,597 @@
+package excelorm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// PageSetup sheet 的页面设置和打印选项
+type PageSetup struct {
+	Landscape    bool   // 是否横向打印, 默认纵向
+	PaperSize    int    // 纸张大小, 见 excelize.PageLayoutOptions.Size, 如 1 为 Letter, 9 为 A4, 为0时为默认纸张
+	FitToWidth   bool   // 是否缩放至一页宽, 高度不限
+	RepeatHeader bool   // 是否每页重复打印表头行
+	PrintArea    string // 打印区域, 如 "A1:F20", 为空时打印全部内容
+}
+
+// WithPageSetup 设置 sheet 的页面和打印选项, 以便生成的报表无需手动设置即可正确打印, sheet 为空时应用于所有 sheet,
+// 同时设置了空 sheet 和具体 sheet 时, 具体 sheet 的设置生效
+func WithPageSetup(sheet string, setup PageSetup) Option {
+	return func(options *options) {
+		if options.pageSetups == nil {
+			options.pageSetups = make(map[string]PageSetup)
+		}
+		options.pageSetups[sanitizeSheetName(sheet)] = setup
+	}
+}
+
+// setPageSetups applies page setups set by WithPageSetup
+func setPageSetups(f *excelize.File, options *options) error {
+	for sheet := range options.pageSetups {
+		if _, ok := options.sheetLayouts[sheet]; sheet != "" && !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	for _, sheet := range f.GetSheetList() {
+		layout, ok := options.sheetLayouts[sheet]
+		if !ok {
+			continue
+		}
+		setup, ok := options.pageSetups[sheet]
+		if !ok {
+			if setup, ok = options.pageSetups[""]; !ok {
+				continue
+			}
+		}
+		if err := setPageSetup(f, sheet, layout, setup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setPageSetup(f *excelize.File, sheet string, layout *sheetLayout, setup PageSetup) error {
+	var pageLayout excelize.PageLayoutOptions
+	if setup.Landscape {
+		orientation := "landscape"
+		pageLayout.Orientation = &orientation
+	}
+	if setup.PaperSize != 0 {
+		pageLayout.Size = &setup.PaperSize
+	}
+	if setup.FitToWidth {
+		fitToPage, width, height := true, 1, 0 // 0 means unlimited pages
+		pageLayout.FitToWidth, pageLayout.FitToHeight = &width, &height
+		if err := f.SetSheetProps(sheet, &excelize.SheetPropsOptions{FitToPage: &fitToPage}); err != nil {
+			return err
+		}
+	}
+	if err := f.SetPageLayout(sheet, &pageLayout); err != nil {
+		return err
+	}
+	if setup.RepeatHeader && layout.headerRows > 0 {
+		if err := f.SetDefinedName(&excelize.DefinedName{
+			Name:     "_xlnm.Print_Titles",
+			RefersTo: fmt.Sprintf("'%s'!$%d:$%d", sheet, layout.row(1), layout.row(layout.headerRows)),
+			Scope:    sheet,
+		}); err != nil {
+			return err
+		}
+	}
+	if setup.PrintArea != "" {
+		rangeRef, err := absoluteRange(setup.PrintArea)
+		if err != nil {
+			return err
+		}
+		if err = f.SetDefinedName(&excelize.DefinedName{
+			Name:     "_xlnm.Print_Area",
+			RefersTo: fmt.Sprintf("'%s'!%s", sheet, rangeRef),
+			Scope:    sheet,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// absoluteRange converts range reference such as "A1:F20" to "$A$1:$F$20"
+func absoluteRange(rangeRef string) (string, error) {
+	cells := strings.Split(rangeRef, ":")
+	if len(cells) != 2 {
+		return "", fmt.Errorf("invalid range %s", rangeRef)
+	}
+	for i, cell := range cells {
+		col, row, err := excelize.CellNameToCoordinates(cell)
+		if err != nil {
+			return "", err
+		}
+		colName, err := columnNumberToName(col)
+		if err != nil {
+			return "", err
+		}
+		cells[i] = "$" + colName + "$" + strconv.Itoa(row)
+	}
+	return strings.Join(cells, ":"), nil
+}
+
+// sheetProtection is a sheet protection set by WithSheetProtection
+type sheetProtection struct {
+	password        string
+	editableHeaders []string
+}
+
+// WithSheetProtection 保护 sheet, 禁止修改单元格, 表头为 editableHeaders 的列(包括数据行以下的空白单元格)仍然可以编辑,
+// 如只允许用户填写部分字段的模板, 带有 tag `excel_editable:"true"` 的字段的列同样可以编辑, password 为取消保护的密码, 可以为空
+// example usage:
+//
+//	type Order struct {
+//		ID     int    `excel_header:"id"`
+//		Amount int    `excel_header:"amount"`
+//		Remark string `excel_header:"remark" excel_editable:"true"`
+//	}
+//
+//	excelorm.WriteExcelSaveAs("orders.xlsx", models, excelorm.WithSheetProtection("orders", ""))
+func WithSheetProtection(sheet, password string, editableHeaders ...string) Option {
+	return func(options *options) {
+		if options.sheetProtections == nil {
+			options.sheetProtections = make(map[string]sheetProtection)
+		}
+		options.sheetProtections[sanitizeSheetName(sheet)] = sheetProtection{password: password, editableHeaders: editableHeaders}
+	}
+}
+
+// protectSheets protects sheets set by WithSheetProtection and unlocks their editable columns
+func protectSheets(f *excelize.File, options *options) error {
+	for sheet, protection := range options.sheetProtections {
+		layout, ok := options.sheetLayouts[sheet]
+		if !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+		editable := make([]bool, len(layout.columns)+1) // whether each column (start from 1) is editable
+		for i, column := range layout.columns {
+			if column.field.Tag.Get("excel_editable") == "true" {
+				editable[i+1] = true
+			}
+		}
+		for _, header := range protection.editableHeaders {
+			col := layout.columnNumber(header)
+			if col == 0 {
+				return fmt.Errorf("column %s not found in sheet %s", header, sheet)
+			}
+			editable[col] = true
+		}
+		rows, err := f.GetRows(sheet) // read once for all editable columns
+		if err != nil {
+			return err
+		}
+		unlockedIDs := make(map[int]int) // style ID of locked cell -> style ID of unlocked cell
+		for col := range editable {
+			if !editable[col] {
+				continue
+			}
+			if err := unlockColumn(f, sheet, col, len(rows), layout, unlockedIDs); err != nil {
+				return err
+			}
+		}
+		if err := f.ProtectSheet(sheet, &excelize.SheetProtectionOptions{
+			Password:            protection.password,
+			SelectLockedCells:   true,
+			SelectUnlockedCells: true,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unlockColumn unlocks cells of column col below the header rows, styles of the cells are kept,
+// rowCount is the number of rows of sheet
+func unlockColumn(f *excelize.File, sheet string, col, rowCount int, layout *sheetLayout, unlockedIDs map[int]int) error {
+	colName, err := columnNumberToName(layout.col(col))
+	if err != nil {
+		return err
+	}
+	// SetColStyle overwrites styles of existing cells in the column, so save them first
+	styleIDs := make([]int, rowCount)
+	for i := range styleIDs {
+		if styleIDs[i], err = f.GetCellStyle(sheet, colName+strconv.Itoa(i+1)); err != nil {
+			return err
+		}
+	}
+	unlockedID, err := unlockedStyleID(f, 0, unlockedIDs)
+	if err != nil {
+		return err
+	}
+	if err = f.SetColStyle(sheet, colName, unlockedID); err != nil {
+		return err
+	}
+	for i, styleID := range styleIDs {
+		row := i + 1
+		if row > layout.row(layout.headerRows) && row <= layout.row(layout.rows) {
+			if styleID, err = unlockedStyleID(f, styleID, unlockedIDs); err != nil {
+				return err
+			}
+		} else if styleID == 0 {
+			// excelize falls back to the column style for cells without style, so lock them explicitly
+			if styleID, err = lockedStyleID(f, unlockedIDs); err != nil {
+				return err
+			}
+		}
+		cellName := colName + strconv.Itoa(row)
+		if err = f.SetCellStyle(sheet, cellName, cellName, styleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockedStyleID returns the ID of the default style, but locked explicitly, it is cached in unlockedIDs with key -1
+func lockedStyleID(f *excelize.File, unlockedIDs map[int]int) (int, error) {
+	if lockedID, ok := unlockedIDs[-1]; ok {
+		return lockedID, nil
+	}
+	lockedID, err := f.NewStyle(&excelize.Style{Protection: &excelize.Protection{Locked: true}})
+	if err != nil {
+		return 0, err
+	}
+	unlockedIDs[-1] = lockedID
+	return lockedID, nil
+}
+
+// unlockedStyleID returns the ID of style which is the same as styleID but unlocked
+func unlockedStyleID(f *excelize.File, styleID int, unlockedIDs map[int]int) (int, error) {
+	if unlockedID, ok := unlockedIDs[styleID]; ok {
+		return unlockedID, nil
+	}
+	style, err := f.GetStyle(styleID)
+	if err != nil {
+		return 0, err
+	}
+	style.Protection = &excelize.Protection{Locked: false}
+	unlockedID, err := f.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	unlockedIDs[styleID] = unlockedID
+	return unlockedID, nil
+}
+
+// WithHideGridlines 隐藏 sheets 的网格线, 使生成的报表看起来更像成品而不是原始表格, sheets 为空时应用于所有 sheet
+func WithHideGridlines(sheets ...string) Option {
+	return func(options *options) {
+		options.hideGridlines = true
+		options.gridlineSheets = sanitizeSheetNames(sheets)
+	}
+}
+
+// WithZoom sheets 的缩放比例, 如 85 为 85%, 范围为 10 至 400, sheets 为空时应用于所有 sheet
+func WithZoom(zoom float64, sheets ...string) Option {
+	return func(options *options) {
+		options.zoom = zoom
+		options.zoomSheets = sanitizeSheetNames(sheets)
+	}
+}
+
+// setSheetViews applies WithHideGridlines and WithZoom to sheets
+func setSheetViews(f *excelize.File, options *options) error {
+	for _, sheets := range [][]string{options.gridlineSheets, options.zoomSheets} {
+		for _, sheet := range sheets {
+			if _, ok := options.sheetLayouts[sheet]; !ok {
+				return fmt.Errorf("sheet %s not found", sheet)
+			}
+		}
+	}
+	for _, sheet := range f.GetSheetList() {
+		if _, ok := options.sheetLayouts[sheet]; !ok {
+			continue
+		}
+		sheetOptions := options.forSheet(sheet)
+		var view excelize.ViewOptions
+		if sheetOptions.hideGridlines && appliesTo(sheetOptions.gridlineSheets, sheet) {
+			showGridLines := false
+			view.ShowGridLines = &showGridLines
+		}
+		if zoom := sheetOptions.zoom; zoom != 0 && appliesTo(sheetOptions.zoomSheets, sheet) {
+			if zoom < 10 || zoom > 400 {
+				return fmt.Errorf("zoom %v out of range [10, 400]", zoom)
+			}
+			view.ZoomScale = &zoom
+		}
+		if view == (excelize.ViewOptions{}) {
+			continue
+		}
+		if err := f.SetSheetView(sheet, 0, &view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appliesTo reports whether an option for sheets applies to sheet, empty sheets means all sheets
+func appliesTo(sheets []string, sheet string) bool {
+	return len(sheets) == 0 || containsString(sheets, sheet)
+}
+
+// WithSheetOrder 按 sheets 的顺序排列 sheet, 而不是数据追加的顺序, 未指定的 sheet 按数据追加的顺序排在其后
+func WithSheetOrder(sheets ...string) Option {
+	return func(options *options) {
+		options.sheetOrder = sanitizeSheetNames(sheets)
+	}
+}
+
+// sheetTitle is a title row set by WithSheetTitle
+type sheetTitle struct {
+	title string
+	style *excelize.Style
+}
+
+// defaultTitleStyle is the style of title row if style of WithSheetTitle is nil
+var defaultTitleStyle = &excelize.Style{
+	Font:      &excelize.Font{Bold: true, Size: 14},
+	Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+}
+
+// WithSheetTitle 在 sheet 的表头上方插入一行合并了所有列的标题行, 表头和数据依次下移一行,
+// style 为标题行的样式, 为 nil 时为加粗居中; 冻结表头, 筛选等选项将标题行视为表头的一部分
+func WithSheetTitle(sheet, title string, style *excelize.Style) Option {
+	return func(options *options) {
+		if options.sheetTitles == nil {
+			options.sheetTitles = make(map[string]sheetTitle)
+		}
+		options.sheetTitles[sanitizeSheetName(sheet)] = sheetTitle{title: title, style: style}
+	}
+}
+
+// writeTitle writes title to the first row of layout, and merges the cells of all columns
+func writeTitle(f *excelize.File, sheet string, layout *sheetLayout, title sheetTitle, options *options) error {
+	columns := len(layout.columns)
+	if columns == 0 {
+		columns = 1
+	}
+	hCell, err := layout.cellName(1, 1)
+	if err != nil {
+		return err
+	}
+	vCell, err := layout.cellName(columns, 1)
+	if err != nil {
+		return err
+	}
+	style := title.style
+	if style == nil {
+		style = defaultTitleStyle
+	}
+	styleID, err := f.NewStyle(style)
+	if err != nil {
+		return err
+	}
+	if layout.stream != nil {
+		cells := make([]interface{}, columns)
+		for i := range cells {
+			cells[i] = excelize.Cell{StyleID: styleID}
+		}
+		cells[0] = excelize.Cell{StyleID: styleID, Value: title.title}
+		if err = layout.stream.SetRow(hCell, cells, excelize.RowOpts{Height: options.headerRowHeight}); err != nil {
+			return err
+		}
+		if columns > 1 {
+			return layout.stream.MergeCell(hCell, vCell)
+		}
+		return nil
+	}
+	if err = f.SetCellValue(sheet, hCell, title.title); err != nil {
+		return err
+	}
+	if columns > 1 {
+		if err = f.MergeCell(sheet, hCell, vCell); err != nil {
+			return err
+		}
+	}
+	return f.SetCellStyle(sheet, hCell, vCell, styleID)
+}
+
+// maxSheetNameLength is the max number of characters of sheet name allowed by excel
+const maxSheetNameLength = 31
+
+// invalidSheetNameChars are characters not allowed in sheet name by excel
+const invalidSheetNameChars = `:\/?*[]`
+
+// WithStrictSheetNames sheet 名称不合法(超过31个字符, 包含 : \ / ? * [ ] 或以单引号开头结尾)时返回错误,
+// 默认将不合法的字符替换为 "_" 并截断为31个字符
+func WithStrictSheetNames() Option {
+	return func(options *options) {
+		options.strictSheetNames = true
+	}
+}
+
+// WithSheetPartitioner 由 partitioner 的返回值决定每行数据写入的 sheet, 覆盖 SheetName(), 返回空字符串时使用 SheetName(),
+// 如按月份或地区将同一类型的数据拆分到多个 sheet
+// example usage:
+//
+//	excelorm.WithSheetPartitioner(func(model excelorm.SheetModel) string {
+//		return model.(Order).CreatedAt.Format("2006-01")
+//	})
+func WithSheetPartitioner(partitioner func(model SheetModel) string) Option {
+	return func(options *options) {
+		options.sheetPartitioner = partitioner
+	}
+}
+
+// getSheetName returns the sheet name of model, it is sanitized unless options.strictSheetNames is set
+func getSheetName(model SheetModel, options *options) (string, error) {
+	var sheetName string
+	if options.sheetPartitioner != nil {
+		sheetName = options.sheetPartitioner(model)
+	}
+	if sheetName == "" {
+		sheetName = model.SheetName()
+	}
+	if sheetName == "" {
+		return "", ErrEmptySheetName
+	}
+	if options.strictSheetNames {
+		return sheetName, checkSheetName(sheetName)
+	}
+	if sanitized := sanitizeSheetName(sheetName); sanitized != sheetName {
+		options.warn("sheet name %s is invalid, it is replaced by %s", sheetName, sanitized)
+		return sanitized, nil
+	}
+	return sheetName, nil
+}
+
+// checkSheetName returns a descriptive error if name is not a valid sheet name
+func checkSheetName(name string) error {
+	if length := utf8.RuneCountInString(name); length > maxSheetNameLength {
+		return fmt.Errorf("sheet name %s has %d characters, exceeds the limit %d", name, length, maxSheetNameLength)
+	}
+	if i := strings.IndexAny(name, invalidSheetNameChars); i >= 0 {
+		return fmt.Errorf("sheet name %s contains invalid character %q", name, name[i])
+	}
+	if strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return fmt.Errorf("sheet name %s can not start or end with '", name)
+	}
+	return nil
+}
+
+// sanitizeSheetName replaces invalid characters of name with "_" and truncates it to maxSheetNameLength characters
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(invalidSheetNameChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if strings.HasPrefix(name, "'") {
+		name = "_" + name[1:]
+	}
+	if runes := []rune(name); len(runes) > maxSheetNameLength {
+		name = string(runes[:maxSheetNameLength])
+	}
+	if strings.HasSuffix(name, "'") {
+		name = name[:len(name)-1] + "_"
+	}
+	return name
+}
+
+func sanitizeSheetNames(names []string) []string {
+	sanitized := make([]string, len(names))
+	for i, name := range names {
+		sanitized[i] = sanitizeSheetName(name)
+	}
+	return sanitized
+}
+
+// WithAutoSplitSheets sheet 的行数超过 excel 的最大行数(1048576)时, 继续写入名为 "sheet (2)", "sheet (3)" 的新 sheet,
+// 新 sheet 同样有表头, 默认返回错误; 按 sheet 指定的选项(如 WithSheetTitle)对新 sheet 不生效
+func WithAutoSplitSheets() Option {
+	return func(options *options) {
+		options.autoSplitSheets = true
+	}
+}
+
+// splitSheetName returns the name of the nth sheet split from sheet, such as "sheet (2)",
+// sheet is truncated to keep the name within maxSheetNameLength characters
+func splitSheetName(sheet string, n int) string {
+	suffix := " (" + strconv.Itoa(n) + ")"
+	if runes := []rune(sheet); len(runes)+len(suffix) > maxSheetNameLength {
+		sheet = string(runes[:maxSheetNameLength-len(suffix)])
+	}
+	return sheet + suffix
+}
+
+// SheetCollisionPolicy 不同类型的数据写入同一个 sheet 且表头不一致时的处理方式, 表头一致时总是按同一个表格写入
+type SheetCollisionPolicy int
+
+const (
+	SheetCollisionAppend    SheetCollisionPolicy = iota // 按第一种类型的表头继续写入, 通过 WithLogger 输出警告
+	SheetCollisionError                                 // 返回错误, 与 WithContinueOnError 同时使用时跳过该行
+	SheetCollisionNewHeader                             // 类型变化时先写入新类型的表头, 使其数据与表头对齐, WithHeadless 时同 SheetCollisionAppend
+)
+
+// WithSheetCollisionPolicy 设置不同类型的数据写入同一个 sheet 且表头不一致时的处理方式, 默认为 SheetCollisionAppend
+func WithSheetCollisionPolicy(policy SheetCollisionPolicy) Option {
+	return func(options *options) {
+		options.collisionPolicy = policy
+	}
+}
+
+// checkSheetCollision checks whether columns of modelType match the current header block of layout,
+// if not, it returns an error or writes a new header block according to options.collisionPolicy
+func checkSheetCollision(f *excelize.File, sheetName string, layout *sheetLayout, modelType reflect.Type, columns []column,
+	options *options) error {
+	if modelType == layout.blockType {
+		return nil
+	}
+	headers := columnHeaders(columns)
+	if len(headers) == len(layout.headers) {
+		matched := true
+		for i, header := range headers {
+			if header != layout.headers[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return nil
+		}
+	}
+	switch options.collisionPolicy {
+	case SheetCollisionError:
+		return fmt.Errorf("headers of %s do not match headers of %s in sheet %s", modelType, layout.blockType, sheetName)
+	case SheetCollisionNewHeader:
+		if options.headless {
+			break
+		}
+		line := layout.rows + 1
+		if err := writeHeader(f, sheetName, layout, line, headers, options); err != nil {
+			return err
+		}
+		if err := addHeaderComments(f, sheetName, layout, line, columns, options); err != nil {
+			return err
+		}
+		layout.rows, layout.blockRow = line, line
+		layout.blockType, layout.headers = modelType, headers
+		return nil
+	}
+	options.warn("sheet %s has rows of both %s and %s", sheetName, layout.blockType, modelType)
+	return nil
+}
+
+// WithSheetBackground 将 imagePath 的图片平铺为 sheet 的背景, 如 "CONFIDENTIAL" 水印, sheet 为空时应用于所有 sheet,
+// 同时设置了空 sheet 和具体 sheet 时, 具体 sheet 的设置生效; 背景只在 excel 中显示, 不会打印
+func WithSheetBackground(sheet, imagePath string) Option {
+	return func(options *options) {
+		if options.sheetBackgrounds == nil {
+			options.sheetBackgrounds = make(map[string]string)
+		}
+		options.sheetBackgrounds[sanitizeSheetName(sheet)] = imagePath
+	}
+}
+
+// setSheetBackgrounds applies backgrounds set by WithSheetBackground
+func setSheetBackgrounds(f *excelize.File, options *options) error {
+	for sheet := range options.sheetBackgrounds {
+		if _, ok := options.sheetLayouts[sheet]; sheet != "" && !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	for _, sheet := range f.GetSheetList() {
+		if _, ok := options.sheetLayouts[sheet]; !ok {
+			continue
+		}
+		imagePath, ok := options.sheetBackgrounds[sheet]
+		if !ok {
+			if imagePath, ok = options.sheetBackgrounds[""]; !ok {
+				continue
+			}
+		}
+		if err := f.SetSheetBackground(sheet, imagePath); err != nil {
+			return fmt.Errorf("background of sheet %s: %w", sheet, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,450 @@
+package excelorm
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func definedNames(f *excelize.File) map[string]string {
+	names := make(map[string]string)
+	for _, name := range f.GetDefinedName() {
+		names[name.Scope+" "+name.Name] = name.RefersTo
+	}
+	return names
+}
+
+func TestWithPageSetup(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet1{Col1: "bar"},
+	}
+	f, err := write(models,
+		WithPageSetup("", PageSetup{PaperSize: 9, RepeatHeader: true}),
+		WithPageSetup("sheet30", PageSetup{Landscape: true, FitToWidth: true, RepeatHeader: true, PrintArea: "A1:B20"}),
+	)
+	require.NoError(t, err)
+	layout, err := f.GetPageLayout("sheet30")
+	require.NoError(t, err)
+	assert.Equal(t, "landscape", *layout.Orientation)
+	assert.Equal(t, 1, *layout.FitToWidth)
+	assert.Equal(t, 0, *layout.FitToHeight)
+	props, err := f.GetSheetProps("sheet30")
+	require.NoError(t, err)
+	assert.True(t, *props.FitToPage)
+	layout, err = f.GetPageLayout("sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, "portrait", *layout.Orientation)
+	assert.Equal(t, 9, *layout.Size)
+	assert.Equal(t, map[string]string{
+		"sheet30 _xlnm.Print_Titles": "'sheet30'!$1:$1",
+		"sheet30 _xlnm.Print_Area":   "'sheet30'!$A$1:$B$20",
+		"sheet1 _xlnm.Print_Titles":  "'sheet1'!$1:$1",
+	}, definedNames(f))
+
+	f, err = write(models, WithHeadless(), WithPageSetup("sheet30", PageSetup{RepeatHeader: true}))
+	require.NoError(t, err)
+	assert.Empty(t, f.GetDefinedName())
+
+	_, err = write(models, WithPageSetup("sheet31", PageSetup{Landscape: true}))
+	require.EqualError(t, err, "sheet sheet31 not found")
+	_, err = write(models, WithPageSetup("sheet30", PageSetup{PrintArea: "A1"}))
+	require.EqualError(t, err, "invalid range A1")
+}
+
+func TestWithSheetBackground(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, png.Encode(&buffer, image.NewGray(image.Rect(0, 0, 2, 2))))
+	path := filepath.Join(t.TempDir(), "confidential.png")
+	require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0o644))
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet1{Col1: "bar"},
+	}
+	backgrounds := func(f *excelize.File) int {
+		data, err := f.WriteToBuffer()
+		require.NoError(t, err)
+		archive, err := zip.NewReader(bytes.NewReader(data.Bytes()), int64(data.Len()))
+		require.NoError(t, err)
+		n := 0
+		for _, file := range archive.File {
+			if strings.HasPrefix(file.Name, "xl/worksheets/sheet") {
+				reader, err := file.Open()
+				require.NoError(t, err)
+				sheetXML, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				if strings.Contains(string(sheetXML), "<picture ") {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	f, err := write(models, WithSheetBackground("sheet30", path))
+	require.NoError(t, err)
+	assert.Equal(t, 1, backgrounds(f))
+	f, err = write(models, WithSheetBackground("", path))
+	require.NoError(t, err)
+	assert.Equal(t, 2, backgrounds(f))
+
+	_, err = write(models, WithSheetBackground("sheet31", path))
+	require.EqualError(t, err, "sheet sheet31 not found")
+	_, err = write(models, WithSheetBackground("sheet30", filepath.Join(t.TempDir(), "foo.png")))
+	require.Error(t, err)
+	_, err = write(models, WithSheetBackground("sheet30", path), WithTempFileThreshold(0))
+	require.EqualError(t, err, "WithSheetBackground is not supported with WithTempFileThreshold")
+}
+
+func isLocked(t *testing.T, f *excelize.File, sheet, cell string) bool {
+	t.Helper()
+	style, err := f.GetStyle(getCellStyle(t, f, sheet, cell))
+	require.NoError(t, err)
+	return style.Protection == nil || style.Protection.Locked
+}
+
+func TestWithSheetProtection(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithFloatAsNumber("0.0"), WithSheetProtection("sheet30", "secret", "balance"),
+		WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateSum}))
+	require.NoError(t, err)
+	assert.NoError(t, f.UnprotectSheet("sheet30", "secret"))
+	assert.True(t, isLocked(t, f, "sheet30", "A2"))
+	assert.True(t, isLocked(t, f, "sheet30", "B1"))  // header
+	assert.False(t, isLocked(t, f, "sheet30", "B2")) // data
+	assert.False(t, isLocked(t, f, "sheet30", "B3"))
+	assert.True(t, isLocked(t, f, "sheet30", "B4")) // summary row
+	assert.False(t, isLocked(t, f, "sheet30", "B100"))
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet30", "B2"))
+	require.NoError(t, err)
+	require.NotNil(t, style.CustomNumFmt)
+	assert.Equal(t, "0.0", *style.CustomNumFmt) // keep style of cell
+
+	f, err = write(models, WithSheetProtection("sheet30", ""))
+	require.NoError(t, err)
+	assert.NoError(t, f.UnprotectSheet("sheet30"))
+
+	_, err = write(models, WithSheetProtection("sheet30", "", "amount"))
+	require.EqualError(t, err, "column amount not found in sheet sheet30")
+	_, err = write(models, WithSheetProtection("sheet31", ""))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+type Sheet43 struct {
+	ID     int    `excel_header:"id"`
+	Amount int    `excel_header:"amount"`
+	Remark string `excel_header:"remark" excel_editable:"true"`
+}
+
+func (Sheet43) SheetName() string {
+	return "sheet43"
+}
+
+func TestEditableTag(t *testing.T) {
+	models := []SheetModel{
+		Sheet43{ID: 1, Amount: 10},
+		Sheet43{ID: 2, Amount: 20, Remark: "foo"},
+	}
+	f, err := write(models, WithSheetProtection("sheet43", "", "amount"))
+	require.NoError(t, err)
+	assert.NoError(t, f.UnprotectSheet("sheet43"))
+	assert.True(t, isLocked(t, f, "sheet43", "A2"))
+	assert.False(t, isLocked(t, f, "sheet43", "B2"))
+	assert.True(t, isLocked(t, f, "sheet43", "C1")) // header
+	assert.False(t, isLocked(t, f, "sheet43", "C2"))
+	assert.False(t, isLocked(t, f, "sheet43", "C3"))
+	assert.False(t, isLocked(t, f, "sheet43", "C100"))
+
+	f, err = write(models) // not protected
+	require.NoError(t, err)
+	assert.True(t, isLocked(t, f, "sheet43", "C2"))
+}
+
+func TestSheetViews(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet1{Col1: "bar"},
+	}
+	f, err := write(models, WithHideGridlines(), WithZoom(85, "sheet30"))
+	require.NoError(t, err)
+	view, err := f.GetSheetView("sheet30", 0)
+	require.NoError(t, err)
+	assert.False(t, *view.ShowGridLines)
+	assert.Equal(t, float64(85), *view.ZoomScale)
+	view, err = f.GetSheetView("sheet1", 0)
+	require.NoError(t, err)
+	assert.False(t, *view.ShowGridLines)
+	assert.Equal(t, float64(100), *view.ZoomScale)
+
+	f, err = write(models, WithHideGridlines("sheet1"))
+	require.NoError(t, err)
+	view, err = f.GetSheetView("sheet30", 0)
+	require.NoError(t, err)
+	assert.True(t, *view.ShowGridLines)
+
+	_, err = write(models, WithZoom(500))
+	require.EqualError(t, err, "zoom 500 out of range [10, 400]")
+	_, err = write(models, WithHideGridlines("sheet31"))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+func TestWithSheetTitle(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithSheetTitle("sheet30", "Monthly Report", nil), WithSheetTitle("sheet29", "Empty", nil),
+		WithSheetHeaders(Sheet29{}), WithFreezeHeader(), WithAutoFilter(), WithAutoFitColumns(), WithZebraStripes("#F2F2F2"),
+		WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateSum}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Monthly Report"},
+		{"name", "balance"},
+		{"foo", "-1.00"},
+		{"bar", "2.00"},
+		{"Total", ""},
+	}, getRows(t, f, "sheet30"))
+	cells, err := f.GetMergeCells("sheet30")
+	require.NoError(t, err)
+	require.Len(t, cells, 1)
+	assert.Equal(t, "A1", cells[0].GetStartAxis())
+	assert.Equal(t, "B1", cells[0].GetEndAxis())
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet30", "A1"))
+	require.NoError(t, err)
+	assert.True(t, style.Font.Bold)
+	formula, err := f.GetCellFormula("sheet30", "B5")
+	require.NoError(t, err)
+	assert.Equal(t, "SUM(B3:B4)", formula)
+	assert.Zero(t, getCellStyle(t, f, "sheet30", "A3"))
+	assert.NotZero(t, getCellStyle(t, f, "sheet30", "A4")) // zebra starts from the second data row
+	panes, err := f.GetPanes("sheet30")
+	require.NoError(t, err)
+	assert.Equal(t, "A3", panes.TopLeftCell)
+	assert.Equal(t, "'sheet30'!$A$2:$B$4", definedNames(f)["sheet30 _xlnm._FilterDatabase"])
+	assert.Equal(t, float64(7), getColWidth(t, f, "sheet30", "A")) // title is not fitted
+	assert.Equal(t, [][]string{{"Empty"}, {"name", "描述", "Empty"}}, getRows(t, f, "sheet29"))
+
+	f, err = write(models, WithHeadless(), WithSheetTitle("sheet30", "Monthly Report", &excelize.Style{}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Monthly Report"}, {"foo", "-1.00"}, {"bar", "2.00"}}, getRows(t, f, "sheet30"))
+
+	_, err = write(models, WithSheetTitle("sheet31", "Monthly Report", nil))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+type Sheet33 struct {
+	Name  string `excel_header:"name"`
+	Level int    `excel_header:"level"`
+}
+
+func (Sheet33) SheetName() string {
+	return "sheet33"
+}
+
+func (s Sheet33) GroupLevel() int {
+	return s.Level
+}
+
+func TestRowGroup(t *testing.T) {
+	models := []SheetModel{
+		Sheet33{Name: "east", Level: 0},
+		Sheet33{Name: "store 1", Level: 1},
+		Sheet33{Name: "sku 1", Level: 2},
+		Sheet33{Name: "sku 2", Level: 2},
+		Sheet33{Name: "store 2", Level: 1},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	for row, want := range map[int]uint8{1: 0, 2: 0, 3: 1, 4: 2, 5: 2, 6: 1} {
+		level, err := f.GetRowOutlineLevel("sheet33", row)
+		require.NoError(t, err)
+		assert.Equal(t, want, level, row)
+	}
+
+	f, err = write(models, WithRowGroupFunc(func(sheet string, row int, model SheetModel) int {
+		if model.(Sheet33).Name == "east" {
+			return 3
+		}
+		return 0
+	}))
+	require.NoError(t, err)
+	level, err := f.GetRowOutlineLevel("sheet33", 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), level)
+	level, err = f.GetRowOutlineLevel("sheet33", 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), level)
+
+	_, err = write([]SheetModel{Sheet33{Level: 8}})
+	require.EqualError(t, err, "group level 8 out of range [0, 7]")
+}
+
+func TestWithSheetOrder(t *testing.T) {
+	models := []SheetModel{
+		Sheet2{Col1: "foo"},
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet1{Col1: "bar"},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithSheetOrder("sheet30", "sheet1"), WithSheetHeaders(Sheet29{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet30", "sheet1", "sheet2", "sheet29"}, f.GetSheetList())
+	assert.Len(t, getRows(t, f, "sheet30"), 3)
+	assert.Equal(t, "bar", getCellValue(t, f, "sheet1", "A2"))
+
+	f, err = write(models, WithSheetOrder("sheet29", "sheet2"), WithSheetHeaders(Sheet29{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet29", "sheet2", "sheet30", "sheet1"}, f.GetSheetList())
+
+	_, err = write(models, WithSheetOrder("sheet31"))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+type Sheet34 struct {
+	Sheet string `excel_header:"-"`
+	Name  string `excel_header:"name"`
+}
+
+func (s Sheet34) SheetName() string {
+	return s.Sheet
+}
+
+func TestSheetNames(t *testing.T) {
+	models := []SheetModel{
+		Sheet34{Sheet: "2024/01: orders [draft]?", Name: "foo"},
+		Sheet34{Sheet: "'quoted'", Name: "bar"},
+		Sheet34{Sheet: "a very long sheet name exceeds the limit of excel", Name: "baz"},
+	}
+	f, err := write(models, WithFreezeHeader(), WithZoom(85, "2024/01: orders [draft]?"),
+		WithSheetTitle("a very long sheet name exceeds the limit of excel", "title", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024_01_ orders _draft__", "_quoted_", "a very long sheet name exceeds "}, f.GetSheetList())
+	assert.Equal(t, "foo", getCellValue(t, f, "2024_01_ orders _draft__", "A2"))
+	view, err := f.GetSheetView("2024_01_ orders _draft__", 0)
+	require.NoError(t, err)
+	assert.Equal(t, float64(85), *view.ZoomScale)
+	assert.Equal(t, "title", getCellValue(t, f, "a very long sheet name exceeds ", "A1"))
+
+	_, err = write(models[:1], WithStrictSheetNames())
+	require.EqualError(t, err, `sheet name 2024/01: orders [draft]? contains invalid character '/'`)
+	_, err = write(models[1:2], WithStrictSheetNames())
+	require.EqualError(t, err, `sheet name 'quoted' can not start or end with '`)
+	_, err = write(models[2:], WithStrictSheetNames())
+	require.EqualError(t, err, `sheet name a very long sheet name exceeds the limit of excel has 49 characters, exceeds the limit 31`)
+	_, err = write([]SheetModel{Sheet34{Sheet: "订单"}}, WithStrictSheetNames())
+	require.NoError(t, err)
+}
+
+func withMaxRows(maxRows int) Option {
+	return func(options *options) {
+		options.maxRows = maxRows
+	}
+}
+
+func TestWithAutoSplitSheets(t *testing.T) {
+	var models []SheetModel
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		models = append(models, Sheet30{Name: name})
+	}
+	models = append(models, Sheet2{Col1: "foo"})
+	f, err := write(models, WithAutoSplitSheets(), withMaxRows(3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet30", "sheet30 (2)", "sheet30 (3)", "sheet2"}, f.GetSheetList())
+	assert.Equal(t, [][]string{{"name", "balance"}, {"a", "0.00"}, {"b", "0.00"}}, getRows(t, f, "sheet30"))
+	assert.Equal(t, [][]string{{"name", "balance"}, {"c", "0.00"}, {"d", "0.00"}}, getRows(t, f, "sheet30 (2)"))
+	assert.Equal(t, [][]string{{"name", "balance"}, {"e", "0.00"}}, getRows(t, f, "sheet30 (3)"))
+
+	_, err = write(models, withMaxRows(3))
+	require.EqualError(t, err, "sheet sheet30 exceeds the limit of 3 rows")
+
+	assert.Equal(t, "a very long sheet name exc (12)", splitSheetName("a very long sheet name exceeds the limit", 12))
+}
+
+func TestWithSheetPartitioner(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+		Sheet30{Name: "baz", Balance: 3},
+		Sheet2{Col1: "foo"},
+	}
+	f, err := write(models, WithSheetPartitioner(func(model SheetModel) string {
+		if s, ok := model.(Sheet30); ok {
+			if s.Balance < 0 {
+				return "negative"
+			}
+			return "positive/zero"
+		}
+		return ""
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"negative", "positive_zero", "sheet2"}, f.GetSheetList())
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "-1.00"}}, getRows(t, f, "negative"))
+	assert.Equal(t, [][]string{{"name", "balance"}, {"bar", "2.00"}, {"baz", "3.00"}}, getRows(t, f, "positive_zero"))
+}
+
+func TestAppendRowsToManySheets(t *testing.T) {
+	models := make([]SheetModel, 100)
+	for i := range models {
+		models[i] = Sheet30{Name: strconv.Itoa(i), Balance: float64(i % 10)}
+	}
+	f, err := write(models, WithSheetPartitioner(func(model SheetModel) string {
+		return fmt.Sprintf("part %g", model.(Sheet30).Balance)
+	}))
+	require.NoError(t, err)
+	sheets := f.GetSheetList()
+	require.Len(t, sheets, 10)
+	for i, sheet := range sheets {
+		assert.Equal(t, fmt.Sprintf("part %d", i), sheet)
+		rows := getRows(t, f, sheet)
+		require.Len(t, rows, 11, sheet)
+		assert.Equal(t, []string{"name", "balance"}, rows[0])
+		assert.Equal(t, []string{strconv.Itoa(90 + i), fmt.Sprintf("%d.00", i)}, rows[10])
+	}
+}
+
+type Sheet38 struct {
+	Label string `excel_header:"name"`
+}
+
+func (Sheet38) SheetName() string {
+	return "sheet38"
+}
+
+func TestWithSheetCollisionPolicy(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet34{Sheet: "sheet30", Name: "bar"},
+		Sheet30{Name: "baz", Balance: 2},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "1.00"}, {"bar"}, {"baz", "2.00"}}, getRows(t, f, "sheet30"))
+
+	f, err = write(models, WithSheetCollisionPolicy(SheetCollisionNewHeader))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "balance"}, {"foo", "1.00"}, {"name"}, {"bar"}, {"name", "balance"}, {"baz", "2.00"}},
+		getRows(t, f, "sheet30"))
+
+	_, err = write(models, WithSheetCollisionPolicy(SheetCollisionError))
+	require.EqualError(t, err, "headers of excelorm.Sheet34 do not match headers of excelorm.Sheet30 in sheet sheet30")
+
+	// headers of different types match
+	f, err = write([]SheetModel{Sheet38{Label: "foo"}, Sheet34{Sheet: "sheet38", Name: "bar"}},
+		WithSheetCollisionPolicy(SheetCollisionError))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name"}, {"foo"}, {"bar"}}, getRows(t, f, "sheet38"))
+}
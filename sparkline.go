@@ -0,0 +1,122 @@
+package excelorm
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sparklineSheet is the hidden sheet holding the series of sparklines, a row per sparkline
+const sparklineSheet = "excelorm_sparklines"
+
+// SparklineType 迷你图的类型
+type SparklineType string
+
+const (
+	SparklineLine    SparklineType = "line"     // 折线迷你图, 默认
+	SparklineColumn  SparklineType = "column"   // 柱形迷你图
+	SparklineWinLoss SparklineType = "win_loss" // 盈亏迷你图
+)
+
+// Sparkline 迷你图类型的字段, Values 以迷你图的形式展示在该字段的单元格中, 用于 KPI 导出中的趋势列;
+// Values 写入隐藏的 sheet excelorm_sparklines, 每个迷你图一行, Values 为空时展示为 WithIfNullValue 设置的空值, 不支持流式写入
+// example usage:
+//
+//	type KPI struct {
+//		Name  string             `excel_header:"name"`
+//		Trend excelorm.Sparkline `excel_header:"trend"`
+//	}
+//	kpi := KPI{Name: "orders", Trend: excelorm.Sparkline{Values: []float64{3, 5, 4, 8}, Markers: true}}
+type Sparkline struct {
+	Values  []float64     // 数据序列
+	Type    SparklineType // 迷你图的类型, 默认为 SparklineLine
+	Markers bool          // 是否显示数据点, 只用于折线迷你图
+	Color   string        // 序列的颜色, 如 "#4472C4", 默认为 excel 的样式
+}
+
+var sparklineType = reflect.TypeOf(Sparkline{})
+
+// rowSparkline is a sparkline of a row, its series is written to sparklineSheet by addSparklines after the row is written
+type rowSparkline struct {
+	series    []float64
+	sparkline *excelize.SparklineOptions
+}
+
+// rowSparklines returns the sparklines in values of the row at line of layout, their cells are left empty
+func rowSparklines(sheetName string, layout *sheetLayout, line int, columns []column, values []interface{},
+	options *options) ([]rowSparkline, error) {
+	var sparklines []rowSparkline
+	for i, value := range values {
+		sparkline, ok := value.(Sparkline)
+		if !ok {
+			continue
+		}
+		values[i] = ""
+		if options.streaming {
+			err := errors.New("sparklines are not supported by stream writers")
+			return nil, &CellError{Sheet: sheetName, Row: layout.row(line), Column: columns[i].header, Field: columns[i].field.Name, Err: err}
+		}
+		cellName, err := layout.cellName(i+1, line)
+		if err != nil {
+			return nil, err
+		}
+		kind := sparkline.Type
+		if kind == "" {
+			kind = SparklineLine
+		}
+		sparklines = append(sparklines, rowSparkline{series: sparkline.Values, sparkline: &excelize.SparklineOptions{
+			Location:    []string{cellName},
+			Type:        string(kind),
+			Markers:     sparkline.Markers,
+			SeriesColor: sparkline.Color,
+		}})
+	}
+	return sparklines, nil
+}
+
+// addSparklines writes the series of sparklines of a written row to sparklineSheet and adds them to sheetName,
+// the series of a row skipped by WithContinueOnError are not written
+func addSparklines(f *excelize.File, sheetName string, sparklines []rowSparkline, options *options) error {
+	for _, sparkline := range sparklines {
+		dataRange, err := writeSparklineData(f, sparkline.series, options)
+		if err != nil {
+			return err
+		}
+		sparkline.sparkline.Range = []string{dataRange}
+		if err = f.AddSparkline(sheetName, sparkline.sparkline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSparklineData appends series to sparklineSheet and returns the reference of its cells
+func writeSparklineData(f *excelize.File, series []float64, options *options) (string, error) {
+	row := *options.sparklineRows + 1
+	if row == 1 {
+		if _, err := f.NewSheet(sparklineSheet); err != nil {
+			return "", err
+		}
+		if err := f.SetSheetVisible(sparklineSheet, false); err != nil {
+			return "", err
+		}
+	}
+	hCell, err := coordinatesToCellName(1, row)
+	if err != nil {
+		return "", err
+	}
+	vCell, err := coordinatesToCellName(len(series), row)
+	if err != nil {
+		return "", err
+	}
+	cells := make([]interface{}, len(series))
+	for i, value := range series {
+		cells[i] = value
+	}
+	if err = f.SetSheetRow(sparklineSheet, hCell, &cells); err != nil {
+		return "", err
+	}
+	*options.sparklineRows = row
+	return chartRef(sparklineSheet, hCell) + ":" + absoluteCell(vCell), nil
+}
@@ -0,0 +1,78 @@
+package excelorm
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Sheet42 struct {
+	Name  string    `excel_header:"name"`
+	Trend Sparkline `excel_header:"trend"`
+}
+
+func (Sheet42) SheetName() string {
+	return "sheet42"
+}
+
+func TestSparkline(t *testing.T) {
+	models := []SheetModel{
+		Sheet42{Name: "foo", Trend: Sparkline{Values: []float64{1, 3, 2}, Markers: true}},
+		Sheet42{Name: "bar"},
+		Sheet42{Name: "baz", Trend: Sparkline{Values: []float64{-1, 1}, Type: SparklineWinLoss}},
+	}
+	f, err := write(models, WithIfNullValue("-"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet42", sparklineSheet}, f.GetSheetList())
+	visible, err := f.GetSheetVisible(sparklineSheet)
+	require.NoError(t, err)
+	assert.False(t, visible)
+	assert.Equal(t, [][]string{{"name", "trend"}, {"foo"}, {"bar", "-"}, {"baz"}}, getRows(t, f, "sheet42"))
+	assert.Equal(t, [][]string{{"1", "3", "2"}, {"-1", "1"}}, getRows(t, f, sparklineSheet))
+	buffer, err := f.WriteToBuffer()
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	require.NoError(t, err)
+	var sheetXML []byte
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, "xl/worksheets/") {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			sheetXML = append(sheetXML, data...)
+		}
+	}
+	assert.Contains(t, string(sheetXML), "<xm:f>&#39;excelorm_sparklines&#39;!$A$1:$C$1</xm:f><xm:sqref>B2</xm:sqref>")
+	assert.Contains(t, string(sheetXML), "<xm:f>&#39;excelorm_sparklines&#39;!$A$2:$B$2</xm:f><xm:sqref>B4</xm:sqref>")
+
+	var cellErr *CellError
+	_, err = write(models[:1], WithTempFileThreshold(0))
+	require.ErrorAs(t, err, &cellErr)
+	assert.Equal(t, "trend", cellErr.Column)
+
+	// the series of a skipped row are not written
+	f, err = write([]SheetModel{
+		Sheet50{Name: "foo", Trend: Sparkline{Values: []float64{1, 2}}, Photo: Image{Data: []byte("foo"), Extension: ".png"}},
+		Sheet50{Name: "bar", Trend: Sparkline{Values: []float64{3, 4}}},
+	}, WithContinueOnError())
+	var rowErrors RowErrors
+	require.ErrorAs(t, err, &rowErrors)
+	require.Len(t, rowErrors, 1)
+	assert.Equal(t, [][]string{{"3", "4"}}, getRows(t, f, sparklineSheet))
+}
+
+type Sheet50 struct {
+	Name  string    `excel_header:"name"`
+	Trend Sparkline `excel_header:"trend"`
+	Photo Image     `excel_header:"photo"`
+}
+
+func (Sheet50) SheetName() string {
+	return "sheet50"
+}
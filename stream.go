@@ -0,0 +1,171 @@
+package excelorm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// estimatedCellSize is the approximate number of bytes held in memory by excelize for a cell
+const estimatedCellSize = 100
+
+// WithTempFileThreshold 数据的估算大小(单元格数 × 每个单元格约100字节)不小于 size 时使用 excelize 的流式写入,
+// 每个 sheet 超过 16MB 的数据写入临时文件而不是全部保存在内存中, 避免导出上百万行数据时内存不足, size 为0时总是使用流式写入;
+// 流式写入时不支持 WriteExcelIntoTemplate, WithSummaryRow, WithAutoFitColumns, WithConditionalFormat, WithAutoFilter,
+// WithPageSetup, WithSheetProtection, WithHideGridlines, WithZoom, WithChart,
+// WithSheetBackground, WithBoolAsCheckbox, WithColumnValidation 和 CellOverflowComment, 使用时返回错误
+func WithTempFileThreshold(size int64) Option {
+	return func(options *options) {
+		if size < 0 {
+			size = 0
+		}
+		options.tempFileThreshold = size
+	}
+}
+
+// useStreamWriter reports whether sheetModels are large enough to be written by stream writers
+func useStreamWriter(sheetModels []SheetModel, options *options) (bool, error) {
+	if options.tempFileThreshold < 0 {
+		return false, nil
+	}
+	var size int64
+	for _, sheetModel := range sheetModels {
+		if sheetModel == nil {
+			continue // rejected when it is written
+		}
+		modelType := indirectType(valueOfModel(sheetModel).Type())
+		if modelType.Kind() != reflect.Struct {
+			continue
+		}
+		columns, err := parseColumns(modelType, options)
+		if err != nil {
+			return false, err
+		}
+		size += int64(len(columns)) * estimatedCellSize
+		if size >= options.tempFileThreshold {
+			return true, nil
+		}
+	}
+	return size >= options.tempFileThreshold, nil
+}
+
+// checkStreamOptions returns an error if options of sheet can not be applied by stream writers
+func checkStreamOptions(sheet string, options *options) error {
+	var option string
+	switch {
+	case len(options.summaryRows[sheet]) > 0:
+		option = "WithSummaryRow"
+	case options.autoFitColumns:
+		option = "WithAutoFitColumns"
+	case len(options.conditionalRules) > 0:
+		option = "WithConditionalFormat"
+	case options.autoFilter:
+		option = "WithAutoFilter"
+	case len(options.pageSetups) > 0:
+		option = "WithPageSetup"
+	case len(options.sheetProtections) > 0:
+		option = "WithSheetProtection"
+	case options.hideGridlines:
+		option = "WithHideGridlines"
+	case options.zoom != 0:
+		option = "WithZoom"
+	case options.overflowPolicy == CellOverflowComment:
+		option = "CellOverflowComment"
+	case len(options.charts) > 0:
+		option = "WithChart"
+	case len(options.sheetBackgrounds) > 0:
+		option = "WithSheetBackground"
+	case options.checkboxes:
+		option = "WithBoolAsCheckbox"
+	case len(options.columnValidations) > 0:
+		option = "WithColumnValidation"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s is not supported with WithTempFileThreshold", option)
+}
+
+// newStreamWriter creates the stream writer of layout, and freezes its panes before any row is written
+func newStreamWriter(f *excelize.File, sheetName string, layout *sheetLayout, withHeader bool, options *options) error {
+	if err := checkStreamOptions(sheetName, options); err != nil {
+		return err
+	}
+	stream, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return err
+	}
+	layout.stream = stream
+	headerRows := 0
+	if _, ok := options.sheetTitles[sheetName]; ok {
+		headerRows++
+	}
+	if withHeader {
+		headerRows += 1 + len(options.subHeaders[sheetName])
+	}
+	panes, err := getPanes(sheetName, layout, headerRows, options)
+	if err != nil || panes == nil {
+		return err
+	}
+	return stream.SetPanes(panes)
+}
+
+// writeRow writes values with styles to line of layout, the outline level of the row is set by opts.OutlineLevel,
+// opts.Height is only used by stream writers, rows written to the workbook are resized by setRowHeights
+func writeRow(f *excelize.File, sheetName string, layout *sheetLayout, line int, values []interface{}, styles []cellStyle,
+	opts excelize.RowOpts, options *options) error {
+	firstCell, err := layout.cellName(1, line)
+	if err != nil {
+		return err
+	}
+	if layout.stream != nil {
+		cells := make([]interface{}, len(values))
+		for i, value := range values {
+			cell, ok := value.(excelize.Cell) // such as the formula of backLink
+			if !ok {
+				cell = excelize.Cell{Value: value}
+			}
+			if styles[i] != (cellStyle{}) || options.fontName != "" || options.fontSize != 0 {
+				if cell.StyleID, err = getStyleID(f, styles[i], options); err != nil {
+					return err
+				}
+			}
+			cells[i] = cell
+		}
+		return layout.stream.SetRow(firstCell, cells, opts)
+	}
+	if opts.OutlineLevel != 0 {
+		if err = f.SetRowOutlineLevel(sheetName, layout.row(line), uint8(opts.OutlineLevel)); err != nil {
+			return err
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if err = f.SetSheetRow(sheetName, firstCell, &values); err != nil { // write the whole row at once
+		return err
+	}
+	for i, style := range styles {
+		cellName, err := layout.cellName(i+1, line)
+		if err != nil {
+			return err
+		}
+		if err = setCellStyle(f, sheetName, cellName, style, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushStreams ends the stream writers of written sheets
+func flushStreams(options *options) error {
+	for _, layout := range options.sheetLayouts {
+		if layout.stream == nil {
+			continue
+		}
+		if err := layout.stream.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
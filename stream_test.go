@@ -0,0 +1,56 @@
+package excelorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWithTempFileThreshold(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet30{Name: "bar", Balance: 2},
+		Sheet33{Name: "baz", Level: 1},
+	}
+	buffer, err := WriteExcelAsBytesBuffer(models, WithTempFileThreshold(0), WithSheetTitle("sheet30", "Balances", nil),
+		WithSubHeaders("sheet30", map[string]string{"balance": "USD"}), WithFreezeHeader(), WithZebraStripes("#EEEEEE"),
+		WithHeaderRowHeight(30), WithDefinedName("balances", "sheet30"))
+	require.NoError(t, err)
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sheet30", "sheet33"}, f.GetSheetList())
+	assert.Equal(t, [][]string{
+		{"Balances"},
+		{"name", "balance"},
+		{"", "USD"},
+		{"foo", "1.00"},
+		{"bar", "2.00"},
+	}, getRows(t, f, "sheet30"))
+	mergeCells, err := f.GetMergeCells("sheet30")
+	require.NoError(t, err)
+	require.Len(t, mergeCells, 1)
+	assert.Equal(t, "A1:B1", mergeCells[0].GetStartAxis()+":"+mergeCells[0].GetEndAxis())
+	panes, err := f.GetPanes("sheet30")
+	require.NoError(t, err)
+	assert.Equal(t, "A4", panes.TopLeftCell)
+	height, err := f.GetRowHeight("sheet30", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, height)
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet30", "A5"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EEEEEE"}, style.Fill.Color)
+	assert.Zero(t, getCellStyle(t, f, "sheet30", "A4"))
+	level, err := f.GetRowOutlineLevel("sheet33", 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), level)
+	assert.Equal(t, map[string]string{"Workbook balances": "'sheet30'!$A$3:$B$5"}, definedNames(f))
+
+	_, err = write(models, WithTempFileThreshold(0), WithAutoFilter())
+	assert.EqualError(t, err, "WithAutoFilter is not supported with WithTempFileThreshold")
+	_, err = WriteExcelIntoTemplate(newTemplate(t), models, WithTempFileThreshold(0))
+	assert.EqualError(t, err, "WriteExcelIntoTemplate is not supported with WithTempFileThreshold")
+	_, err = write(models, WithTempFileThreshold(1<<20), WithAutoFilter()) // small data is written in memory
+	assert.NoError(t, err)
+}
@@ -0,0 +1,363 @@
+package excelorm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// cellStyle describes the style of a cell, cells with the same style share one style ID
+type cellStyle struct {
+	numberFormat string          // custom number format
+	fillColor    string          // background color
+	border       bool            // whether to draw borders set by WithTableBorders
+	custom       *excelize.Style // style returned by options.cellStyleFunc or row style, it takes precedence over the others
+	hidden       bool            // whether to hide the value by the font color of the background, see WithBoolAsCheckbox
+	borderStyle  int             // line style of borders, filled by getStyleID from options of the sheet
+	borderColor  string          // color of borders, filled by getStyleID from options of the sheet
+}
+
+// getStyleID returns the ID of style in f, the style is created at the first time
+func getStyleID(f *excelize.File, style cellStyle, options *options) (int, error) {
+	if style.border {
+		// the cache is shared by sheets, which may set different borders by SheetModelWithOptions
+		style.borderStyle, style.borderColor = options.borderStyle, options.borderColor
+	}
+	if styleID, ok := options.styleIDs[style]; ok {
+		return styleID, nil
+	}
+	format := new(excelize.Style)
+	if style.custom != nil {
+		*format = *style.custom // copy, the custom style is owned by the caller
+	}
+	if style.numberFormat != "" && format.NumFmt == 0 && format.CustomNumFmt == nil {
+		format.CustomNumFmt = &style.numberFormat
+	}
+	if style.fillColor != "" && format.Fill.Type == "" {
+		format.Fill = excelize.Fill{Type: "pattern", Color: []string{style.fillColor}, Pattern: 1}
+	}
+	if options.fontName != "" || options.fontSize != 0 {
+		font := new(excelize.Font)
+		if format.Font != nil {
+			*font = *format.Font
+		}
+		if font.Family == "" {
+			font.Family = options.fontName
+		}
+		if font.Size == 0 {
+			font.Size = options.fontSize
+		}
+		format.Font = font
+	}
+	if style.hidden {
+		font := &excelize.Font{Color: "#FFFFFF"}
+		if format.Font != nil {
+			*font = *format.Font
+			font.Color = "#FFFFFF"
+		}
+		if format.Fill.Type == "pattern" && len(format.Fill.Color) > 0 {
+			font.Color = format.Fill.Color[0]
+		}
+		format.Font = font
+	}
+	if style.border && format.Border == nil {
+		for _, borderType := range []string{"left", "top", "right", "bottom"} {
+			format.Border = append(format.Border, excelize.Border{
+				Type:  borderType,
+				Color: style.borderColor,
+				Style: style.borderStyle,
+			})
+		}
+	}
+	styleID, err := f.NewStyle(format)
+	if err != nil {
+		return 0, err
+	}
+	options.styleIDs[style] = styleID
+	return styleID, nil
+}
+
+// setCellStyle sets style of cell, it does nothing if style is zero and the default font is not set
+func setCellStyle(f *excelize.File, sheet, cell string, style cellStyle, options *options) error {
+	if style == (cellStyle{}) && options.fontName == "" && options.fontSize == 0 {
+		return nil
+	}
+	styleID, err := getStyleID(f, style, options)
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheet, cell, cell, styleID)
+}
+
+// floatNumberFormat returns the number format of float cells written by WithFloatAsNumber
+func floatNumberFormat(options *options) string {
+	if options.floatNumberFormat != "" {
+		return options.floatNumberFormat
+	}
+	numberFormat := "0" // keep the same precision as string
+	if options.floatPrecision > 0 {
+		numberFormat += "." + strings.Repeat("0", options.floatPrecision)
+	}
+	return numberFormat
+}
+
+// defaultMaxColumnWidth is the max column width of WithAutoFitColumns if WithMaxColumnWidth is not set
+const defaultMaxColumnWidth = 60
+
+// autoFitColumns sets width of every column to fit its longest value in sheets with WithAutoFitColumns
+func autoFitColumns(f *excelize.File, options *options) error {
+	for _, sheetName := range f.GetSheetList() {
+		sheetOptions := options.forSheet(sheetName)
+		if !sheetOptions.autoFitColumns {
+			continue
+		}
+		maxWidth := sheetOptions.maxColumnWidth
+		if maxWidth <= 0 {
+			maxWidth = defaultMaxColumnWidth
+		}
+		rows, err := f.GetRows(sheetName)
+		if err != nil {
+			return err
+		}
+		if layout, ok := options.sheetLayouts[sheetName]; ok && len(rows) >= layout.row(layout.titleRows) {
+			// title is merged across columns, so it does not fit a single column, neither do rows of template above
+			rows = rows[layout.row(layout.titleRows):]
+		}
+		var widths []float64
+		for _, row := range rows {
+			for i, value := range row {
+				if i >= len(widths) {
+					widths = append(widths, 0)
+				}
+				if width := displayWidth(value); width > widths[i] {
+					widths[i] = width
+				}
+			}
+		}
+		for i, width := range widths {
+			if width == 0 {
+				continue // keep default width for empty column
+			}
+			width += 2 // padding
+			if width > maxWidth {
+				width = maxWidth
+			}
+			colName, err := columnNumberToName(i + 1)
+			if err != nil {
+				return err
+			}
+			if err = f.SetColWidth(sheetName, colName, colName, width); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// displayWidth returns the width of the longest line of s,
+// East Asian wide characters (such as CJK) are counted as 2
+func displayWidth(s string) float64 {
+	var maxWidth float64
+	for _, line := range strings.Split(s, "\n") {
+		var width float64
+		for _, r := range line {
+			if isWideRune(r) {
+				width += 2
+			} else {
+				width++
+			}
+		}
+		if width > maxWidth {
+			maxWidth = width
+		}
+	}
+	return maxWidth
+}
+
+func isWideRune(r rune) bool {
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) ||
+		unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) {
+		return true
+	}
+	return (r >= 0x3000 && r <= 0x303F) || // CJK symbols and punctuation
+		(r >= 0xFF01 && r <= 0xFF60) || // fullwidth forms
+		(r >= 0xFFE0 && r <= 0xFFE6)
+}
+
+// conditionalRule is a conditional format set by WithConditionalFormat
+type conditionalRule struct {
+	sheet  string
+	header string
+	rule   excelize.ConditionalFormatOptions
+	style  *excelize.Style
+}
+
+// setConditionalFormats applies rules of WithConditionalFormat to the data range of their columns
+func setConditionalFormats(f *excelize.File, options *options) error {
+	for _, rule := range options.conditionalRules {
+		rangeRef, ok, err := columnDataRange(rule.sheet, rule.header, options)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue // no data
+		}
+		if rule.style != nil {
+			styleID, err := f.NewConditionalStyle(rule.style)
+			if err != nil {
+				return err
+			}
+			rule.rule.Format = &styleID
+		}
+		if err = f.SetConditionalFormat(rule.sheet, rangeRef, []excelize.ConditionalFormatOptions{rule.rule}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnDataRange returns the range reference of data cells of the column with header in sheet,
+// such as "B2:B10", it returns false if the sheet has no data
+func columnDataRange(sheet, header string, options *options) (string, bool, error) {
+	layout, ok := options.sheetLayouts[sheet]
+	if !ok {
+		return "", false, fmt.Errorf("sheet %s not found", sheet)
+	}
+	col := layout.columnNumber(header)
+	if col == 0 {
+		return "", false, fmt.Errorf("column %s not found in sheet %s", header, sheet)
+	}
+	if layout.rows <= layout.headerRows {
+		return "", false, nil
+	}
+	hCell, err := layout.cellName(col, layout.headerRows+1)
+	if err != nil {
+		return "", false, err
+	}
+	vCell, err := layout.cellName(col, layout.rows)
+	if err != nil {
+		return "", false, err
+	}
+	return hCell + ":" + vCell, true, nil
+}
+
+// columnNumber returns the number (start from 1) of the column with header, or 0 if not found
+func (l *sheetLayout) columnNumber(header string) int {
+	for i, column := range l.columns {
+		if column.header == header {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// setPanes freezes panes set by WithFreezePanes and header rows if WithFreezeHeader is set
+func setPanes(f *excelize.File, options *options) error {
+	for sheet := range options.freezePanes {
+		if _, ok := options.sheetLayouts[sheet]; !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	for _, sheet := range f.GetSheetList() {
+		layout, ok := options.sheetLayouts[sheet]
+		if !ok {
+			continue
+		}
+		panes, err := getPanes(sheet, layout, layout.headerRows, options.forSheet(sheet))
+		if err != nil {
+			return err
+		}
+		if panes == nil {
+			continue
+		}
+		if err = f.SetPanes(sheet, panes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPanes returns the panes of sheet with headerRows header rows, it returns nil if nothing to freeze
+func getPanes(sheet string, layout *sheetLayout, headerRows int, options *options) (*excelize.Panes, error) {
+	cell, ok := options.freezePanes[sheet]
+	if !ok {
+		if !options.freezeHeader || headerRows == 0 {
+			return nil, nil
+		}
+		cell = "A" + strconv.Itoa(layout.row(headerRows+1))
+	}
+	col, row, err := excelize.CellNameToCoordinates(cell)
+	if err != nil {
+		return nil, err
+	}
+	if col == 1 && row == 1 {
+		return nil, nil // nothing to freeze
+	}
+	activePane := "bottomRight"
+	if col == 1 {
+		activePane = "bottomLeft"
+	} else if row == 1 {
+		activePane = "topRight"
+	}
+	return &excelize.Panes{
+		Freeze:      true,
+		XSplit:      col - 1,
+		YSplit:      row - 1,
+		TopLeftCell: cell,
+		ActivePane:  activePane,
+		Selection:   []excelize.Selection{{SQRef: cell, ActiveCell: cell, Pane: activePane}},
+	}, nil
+}
+
+// setAutoFilters adds auto filter to the last header row of sheets with WithAutoFilter, covering all data rows
+func setAutoFilters(f *excelize.File, options *options) error {
+	for _, sheet := range f.GetSheetList() {
+		layout, ok := options.sheetLayouts[sheet]
+		if !ok || !options.forSheet(sheet).autoFilter || layout.headerRows == layout.titleRows || len(layout.columns) == 0 {
+			continue
+		}
+		hCell, err := layout.cellName(1, layout.headerRows)
+		if err != nil {
+			return err
+		}
+		vCell, err := layout.cellName(len(layout.columns), layout.rows)
+		if err != nil {
+			return err
+		}
+		if err = f.AutoFilter(sheet, hCell+":"+vCell, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setRowHeights sets heights of header rows and data rows set by WithHeaderRowHeight and WithRowHeight
+func setRowHeights(f *excelize.File, options *options) error {
+	for _, sheet := range f.GetSheetList() {
+		layout, ok := options.sheetLayouts[sheet]
+		sheetOptions := options.forSheet(sheet)
+		if !ok || sheetOptions.rowHeight == 0 && sheetOptions.headerRowHeight == 0 {
+			continue
+		}
+		lastRow := layout.rows
+		if _, ok = options.summaryRows[sheet]; ok && layout.rows > layout.headerRows {
+			lastRow++ // summary row
+		}
+		for row := 1; row <= lastRow; row++ {
+			height := sheetOptions.rowHeight
+			if row <= layout.headerRows {
+				height = sheetOptions.headerRowHeight
+			}
+			if height == 0 {
+				continue
+			}
+			if err := f.SetRowHeight(sheet, layout.row(row), height); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
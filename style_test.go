@@ -0,0 +1,444 @@
+package excelorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+type Sheet29 struct {
+	Name        string `excel_header:"name"`
+	Description string `excel_header:"描述"`
+	Empty       string `excel_header:""`
+}
+
+func (Sheet29) SheetName() string {
+	return "sheet29"
+}
+
+func TestWithAutoFitColumns(t *testing.T) {
+	models := []SheetModel{
+		Sheet29{Name: "foo", Description: "中文描述"},
+		Sheet29{Name: "a much longer name", Description: "short\nmulti-line"},
+	}
+	f, err := write(models, WithAutoFitColumns())
+	require.NoError(t, err)
+	assert.InDelta(t, 20, getColWidth(t, f, "sheet29", "A"), 0.01)
+	assert.InDelta(t, 12, getColWidth(t, f, "sheet29", "B"), 0.01)
+	assert.InDelta(t, 7, getColWidth(t, f, "sheet29", "C"), 0.01)
+
+	f, err = write(models, WithAutoFitColumns(), WithMaxColumnWidth(10))
+	require.NoError(t, err)
+	assert.InDelta(t, 10, getColWidth(t, f, "sheet29", "A"), 0.01)
+}
+
+func TestDisplayWidth(t *testing.T) {
+	assert.InDelta(t, 3, displayWidth("foo"), 0.01)
+	assert.InDelta(t, 8, displayWidth("中文描述"), 0.01)
+	assert.InDelta(t, 6, displayWidth("ｆｏｏ"), 0.01)
+	assert.InDelta(t, 4, displayWidth("ab\nabcd\n"), 0.01)
+}
+
+func TestWithZebraStripes(t *testing.T) {
+	models := []SheetModel{
+		Sheet2{Col3: 1}, Sheet2{Col3: 2}, Sheet2{Col3: 3}, Sheet2{Col3: 4},
+		Sheet29{Name: "foo"}, Sheet29{Name: "bar"},
+	}
+	f, err := write(models, WithZebraStripes("#F2F2F2"), WithFloatAsNumber("0.0"))
+	require.NoError(t, err)
+	assert.Zero(t, getCellStyle(t, f, "sheet2", "A1")) // header
+	assert.Zero(t, getCellStyle(t, f, "sheet2", "A2"))
+	striped := getCellStyle(t, f, "sheet2", "A3")
+	assert.NotZero(t, striped)
+	assert.Zero(t, getCellStyle(t, f, "sheet2", "A4"))
+	assert.Equal(t, striped, getCellStyle(t, f, "sheet2", "A5"))
+	assert.Equal(t, striped, getCellStyle(t, f, "sheet29", "B3"))
+
+	// float cell keeps its number format with fill color
+	number := getCellStyle(t, f, "sheet2", "C2")
+	stripedNumber := getCellStyle(t, f, "sheet2", "C3")
+	assert.NotZero(t, number)
+	assert.NotEqual(t, number, stripedNumber)
+	assert.NotEqual(t, striped, stripedNumber)
+
+	f, err = write(models, WithZebraStripes("#F2F2F2"), WithHeadless())
+	require.NoError(t, err)
+	assert.Zero(t, getCellStyle(t, f, "sheet2", "A1"))
+	assert.NotZero(t, getCellStyle(t, f, "sheet2", "A2"))
+}
+
+type Sheet30 struct {
+	Name    string  `excel_header:"name"`
+	Balance float64 `excel_header:"balance"`
+}
+
+func (Sheet30) SheetName() string {
+	return "sheet30"
+}
+
+func TestWithConditionalFormat(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	rule := excelize.ConditionalFormatOptions{Type: "cell", Criteria: "<", Value: "0"}
+	style := &excelize.Style{Font: &excelize.Font{Color: "#FF0000"}}
+	f, err := write(models, WithFloatAsNumber(""),
+		WithConditionalFormat("sheet30", "balance", rule, style),
+		WithConditionalFormat("sheet30", "name", excelize.ConditionalFormatOptions{Type: "duplicate", Criteria: "="}, style),
+		WithConditionalFormat("sheet1", "string", rule, style),
+		WithSheetHeaders(Sheet1{}),
+	)
+	require.NoError(t, err)
+	formats, err := f.GetConditionalFormats("sheet30")
+	require.NoError(t, err)
+	require.Contains(t, formats, "B2:B3")
+	assert.Equal(t, "cell", formats["B2:B3"][0].Type)
+	assert.Equal(t, "less than", formats["B2:B3"][0].Criteria)
+	assert.Contains(t, formats, "A2:A3")
+	formats, err = f.GetConditionalFormats("sheet1")
+	require.NoError(t, err)
+	assert.Empty(t, formats) // no data
+
+	f, err = write(models, WithHeadless(), WithConditionalFormat("sheet30", "balance", rule, nil))
+	require.NoError(t, err)
+	formats, err = f.GetConditionalFormats("sheet30")
+	require.NoError(t, err)
+	assert.Contains(t, formats, "B1:B2")
+
+	_, err = write(models, WithConditionalFormat("sheet30", "amount", rule, style))
+	require.EqualError(t, err, "column amount not found in sheet sheet30")
+	_, err = write(models, WithConditionalFormat("sheet31", "balance", rule, style))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+func TestWithDataBars(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithFloatAsNumber(""), WithDataBars("sheet30", "balance"))
+	require.NoError(t, err)
+	formats, err := f.GetConditionalFormats("sheet30")
+	require.NoError(t, err)
+	require.Len(t, formats["B2:B3"], 1)
+	assert.Equal(t, "data_bar", formats["B2:B3"][0].Type)
+	assert.Equal(t, defaultDataBarColor, formats["B2:B3"][0].BarColor)
+
+	f, err = write(models, WithFloatAsNumber(""), WithColorScale("sheet30", "balance", "#F8696B", "#FFEB84", "#63BE7B"))
+	require.NoError(t, err)
+	formats, err = f.GetConditionalFormats("sheet30")
+	require.NoError(t, err)
+	assert.Equal(t, "3_color_scale", formats["B2:B3"][0].Type)
+	assert.Equal(t, "#FFEB84", formats["B2:B3"][0].MidColor)
+
+	f, err = write(models, WithFloatAsNumber(""), WithColorScale("sheet30", "balance", "#FFFFFF", "", "#63BE7B"))
+	require.NoError(t, err)
+	formats, err = f.GetConditionalFormats("sheet30")
+	require.NoError(t, err)
+	assert.Equal(t, "2_color_scale", formats["B2:B3"][0].Type)
+}
+
+func TestWithFreezePanes(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet1{Col1: "bar"},
+	}
+	f, err := write(models, WithFreezeHeader(), WithFreezePanes("sheet1", "B2"))
+	require.NoError(t, err)
+	panes, err := f.GetPanes("sheet30")
+	require.NoError(t, err)
+	assert.True(t, panes.Freeze)
+	assert.Equal(t, 0, panes.XSplit)
+	assert.Equal(t, 1, panes.YSplit)
+	assert.Equal(t, "A2", panes.TopLeftCell)
+	assert.Equal(t, "bottomLeft", panes.ActivePane)
+	panes, err = f.GetPanes("sheet1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, panes.XSplit)
+	assert.Equal(t, 1, panes.YSplit)
+	assert.Equal(t, "bottomRight", panes.ActivePane)
+
+	f, err = write(models, WithFreezeHeader(), WithHeadless())
+	require.NoError(t, err)
+	panes, err = f.GetPanes("sheet30")
+	require.NoError(t, err)
+	assert.False(t, panes.Freeze)
+
+	_, err = write(models, WithFreezePanes("sheet31", "A2"))
+	require.EqualError(t, err, "sheet sheet31 not found")
+	_, err = write(models, WithFreezePanes("sheet30", "2A"))
+	require.Error(t, err)
+}
+
+func TestWithAutoFilter(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithAutoFilter(), WithSheetHeaders(Sheet1{}))
+	require.NoError(t, err)
+	filters := make(map[string]string)
+	for _, name := range f.GetDefinedName() {
+		assert.Equal(t, "_xlnm._FilterDatabase", name.Name)
+		filters[name.Scope] = name.RefersTo
+	}
+	assert.Equal(t, map[string]string{
+		"sheet30": "'sheet30'!$A$1:$B$3",
+		"sheet1":  "'sheet1'!$A$1:$J$1",
+	}, filters)
+
+	f, err = write(models, WithAutoFilter(), WithHeadless())
+	require.NoError(t, err)
+	assert.Empty(t, f.GetDefinedName())
+
+	f, err = write(models, WithAutoFilter(), WithHeadless(), WithSheetTitle("sheet30", "accounts", nil))
+	require.NoError(t, err)
+	assert.Empty(t, f.GetDefinedName())
+}
+
+func TestWithCellStyleFunc(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+		Sheet30{Name: "baz", Balance: -3},
+	}
+	red := &excelize.Style{Font: &excelize.Font{Color: "#FF0000"}}
+	type call struct {
+		sheet    string
+		row, col int
+		value    interface{}
+	}
+	var calls []call
+	f, err := write(models, WithFloatAsNumber("0.0"), WithZebraStripes("#F2F2F2"),
+		WithCellStyleFunc(func(sheet string, row, col int, value interface{}) *excelize.Style {
+			calls = append(calls, call{sheet, row, col, value})
+			if balance, ok := value.(float64); ok && balance < 0 {
+				return red
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	require.Len(t, calls, 6)
+	assert.Equal(t, call{"sheet30", 2, 1, "foo"}, calls[0])
+	assert.Equal(t, call{"sheet30", 2, 2, float64(-1)}, calls[1])
+	assert.Equal(t, call{"sheet30", 4, 2, float64(-3)}, calls[5])
+
+	assert.Zero(t, getCellStyle(t, f, "sheet30", "A2"))
+	styleID := getCellStyle(t, f, "sheet30", "B2")
+	style, err := f.GetStyle(styleID)
+	require.NoError(t, err)
+	assert.Equal(t, "FF0000", strings.TrimPrefix(style.Font.Color, "#"))
+	require.NotNil(t, style.CustomNumFmt)
+	assert.Equal(t, "0.0", *style.CustomNumFmt) // keep number format
+	assert.Empty(t, style.Fill.Color)
+	assert.Equal(t, styleID, getCellStyle(t, f, "sheet30", "B4")) // share the same style
+	assert.NotEqual(t, styleID, getCellStyle(t, f, "sheet30", "B3"))
+
+	// zebra fill is kept
+	f, err = write(models[1:], WithZebraStripes("#F2F2F2"),
+		WithCellStyleFunc(func(sheet string, row, col int, value interface{}) *excelize.Style {
+			return red
+		}))
+	require.NoError(t, err)
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet30", "B3"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"F2F2F2"}, trimColors(style.Fill.Color))
+	assert.Nil(t, red.CustomNumFmt) // custom style is not modified
+}
+
+func trimColors(colors []string) []string {
+	trimmed := make([]string, len(colors))
+	for i, color := range colors {
+		trimmed[i] = strings.TrimPrefix(color, "#")
+	}
+	return trimmed
+}
+
+var grayRow = &excelize.Style{Font: &excelize.Font{Color: "#808080"}}
+
+type Sheet31 struct {
+	Name      string `excel_header:"name"`
+	Cancelled bool   `excel_header:"cancelled"`
+}
+
+func (Sheet31) SheetName() string {
+	return "sheet31"
+}
+
+func (s *Sheet31) RowStyle() *excelize.Style {
+	if s.Cancelled {
+		return grayRow
+	}
+	return nil
+}
+
+func TestRowStyle(t *testing.T) {
+	models := []SheetModel{
+		Sheet31{Name: "foo", Cancelled: true},
+		Sheet31{Name: "bar"},
+		Sheet31{Name: "baz", Cancelled: true},
+	}
+	f, err := write(models)
+	require.NoError(t, err)
+	gray := getCellStyle(t, f, "sheet31", "A2")
+	assert.NotZero(t, gray)
+	assert.Equal(t, gray, getCellStyle(t, f, "sheet31", "B2"))
+	assert.Zero(t, getCellStyle(t, f, "sheet31", "A3"))
+	assert.Equal(t, gray, getCellStyle(t, f, "sheet31", "B4"))
+
+	red := &excelize.Style{Font: &excelize.Font{Color: "#FF0000"}}
+	bold := &excelize.Style{Font: &excelize.Font{Bold: true}}
+	var rows []int
+	f, err = write(models,
+		WithRowStyleFunc(func(sheet string, row int, model SheetModel) *excelize.Style {
+			rows = append(rows, row)
+			if model.(Sheet31).Name == "bar" {
+				return red
+			}
+			return nil
+		}),
+		WithCellStyleFunc(func(sheet string, row, col int, value interface{}) *excelize.Style {
+			if value == "baz" {
+				return bold
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, rows)
+	assert.Equal(t, gray, getCellStyle(t, f, "sheet31", "A2")) // RowStyler
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet31", "B3"))
+	require.NoError(t, err)
+	assert.Equal(t, "FF0000", strings.TrimPrefix(style.Font.Color, "#")) // WithRowStyleFunc
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet31", "A4"))
+	require.NoError(t, err)
+	assert.True(t, style.Font.Bold) // WithCellStyleFunc
+	assert.Equal(t, gray, getCellStyle(t, f, "sheet31", "B4"))
+}
+
+func TestWithTableBorders(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithTableBorders(0, "#000000"), WithZebraStripes("#F2F2F2"),
+		WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateSum}), WithSheetHeaders(Sheet29{}))
+	require.NoError(t, err)
+	header := getCellStyle(t, f, "sheet30", "A1")
+	style, err := f.GetStyle(header)
+	require.NoError(t, err)
+	require.Len(t, style.Border, 4)
+	assert.Equal(t, 1, style.Border[0].Style)
+	assert.Equal(t, "000000", strings.TrimPrefix(style.Border[0].Color, "#"))
+	assert.Equal(t, header, getCellStyle(t, f, "sheet30", "B2"))
+	assert.Equal(t, header, getCellStyle(t, f, "sheet30", "B4")) // summary row
+	assert.Equal(t, header, getCellStyle(t, f, "sheet29", "C1")) // header of no data sheet
+	assert.Zero(t, getCellStyle(t, f, "sheet30", "C1"))
+	assert.Zero(t, getCellStyle(t, f, "sheet30", "A5"))
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A3"))
+	require.NoError(t, err)
+	assert.Len(t, style.Border, 4)
+	assert.Equal(t, []string{"F2F2F2"}, trimColors(style.Fill.Color)) // keep zebra fill
+
+	f, err = write(models, WithTableBorders(2, ""))
+	require.NoError(t, err)
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A2"))
+	require.NoError(t, err)
+	require.Len(t, style.Border, 4)
+	assert.Equal(t, 2, style.Border[0].Style)
+
+	models = append(models, Sheet48{Name: "baz"})
+	f, err = write(models, WithTableBorders(2, ""))
+	require.NoError(t, err)
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A2"))
+	require.NoError(t, err)
+	require.Len(t, style.Border, 4)
+	assert.Equal(t, 2, style.Border[0].Style)
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet48", "A2"))
+	require.NoError(t, err)
+	require.Len(t, style.Border, 4)
+	assert.Equal(t, 5, style.Border[0].Style)
+	assert.Equal(t, "FF0000", strings.TrimPrefix(style.Border[0].Color, "#"))
+}
+
+type Sheet48 struct {
+	Name string `excel_header:"name"`
+}
+
+func (Sheet48) SheetName() string {
+	return "sheet48"
+}
+
+func (Sheet48) SheetOptions() []Option {
+	return []Option{WithTableBorders(5, "#FF0000")}
+}
+
+func TestWithDefaultFont(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	bold := &excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}}
+	f, err := write(models, WithDefaultFont("宋体", 10), WithFloatAsNumber(""),
+		WithCellStyleFunc(func(sheet string, row, col int, value interface{}) *excelize.Style {
+			if value == "bar" {
+				return bold
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	font, err := f.GetDefaultFont()
+	require.NoError(t, err)
+	assert.Equal(t, "宋体", font)
+	for _, cell := range []string{"A1", "A2", "B2"} {
+		style, err := f.GetStyle(getCellStyle(t, f, "sheet30", cell))
+		require.NoError(t, err)
+		require.NotNil(t, style.Font, cell)
+		assert.Equal(t, "宋体", style.Font.Family, cell)
+		assert.Equal(t, float64(10), style.Font.Size, cell)
+	}
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet30", "A3"))
+	require.NoError(t, err)
+	assert.Equal(t, "宋体", style.Font.Family)
+	assert.Equal(t, float64(14), style.Font.Size)
+	assert.True(t, style.Font.Bold)
+
+	f, err = write(models, WithDefaultFont("", 12))
+	require.NoError(t, err)
+	font, err = f.GetDefaultFont()
+	require.NoError(t, err)
+	assert.Equal(t, "Calibri", font)
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A2"))
+	require.NoError(t, err)
+	assert.Equal(t, float64(12), style.Font.Size)
+}
+
+func TestWithRowHeight(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithRowHeight(30), WithHeaderRowHeight(40),
+		WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateSum}))
+	require.NoError(t, err)
+	for row, want := range map[int]float64{1: 40, 2: 30, 3: 30, 4: 30} {
+		height, err := f.GetRowHeight("sheet30", row)
+		require.NoError(t, err)
+		assert.Equal(t, want, height, row)
+	}
+	height, err := f.GetRowHeight("sheet30", 5)
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, height) // default height
+
+	f, err = write(models, WithRowHeight(30), WithHeadless())
+	require.NoError(t, err)
+	height, err = f.GetRowHeight("sheet30", 1)
+	require.NoError(t, err)
+	assert.Equal(t, float64(30), height)
+
+	_, err = write(models, WithHeaderRowHeight(500))
+	require.Error(t, err)
+}
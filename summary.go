@@ -0,0 +1,82 @@
+package excelorm
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Aggregate 汇总行中列的汇总函数
+type Aggregate string
+
+const (
+	AggregateSum     Aggregate = "SUM"     // 求和
+	AggregateAverage Aggregate = "AVERAGE" // 平均值
+	AggregateCount   Aggregate = "COUNT"   // 数字单元格的个数
+	AggregateCountA  Aggregate = "COUNTA"  // 非空单元格的个数
+	AggregateMin     Aggregate = "MIN"     // 最小值
+	AggregateMax     Aggregate = "MAX"     // 最大值
+)
+
+// summaryLabel is the label of summary row written to the first column
+const summaryLabel = "Total"
+
+// WithSummaryRow 在 sheet 的最后追加一行汇总行, spec 的 key 为表头, value 为该列的汇总函数, 以公式的形式写入, 如 =SUM(B2:B10),
+// 第一列不汇总时写入 "Total"; 数字以外的单元格不参与 SUM 等计算, float 类型的列需要同时设置 WithFloatAsNumber
+func WithSummaryRow(sheet string, spec map[string]Aggregate) Option {
+	return func(options *options) {
+		if options.summaryRows == nil {
+			options.summaryRows = make(map[string]map[string]Aggregate)
+		}
+		options.summaryRows[sanitizeSheetName(sheet)] = spec
+	}
+}
+
+// setSummaryRows appends summary rows set by WithSummaryRow after the data rows
+func setSummaryRows(f *excelize.File, options *options) error {
+	for sheet, spec := range options.summaryRows {
+		layout, ok := options.sheetLayouts[sheet]
+		if !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+		for header, aggregate := range spec {
+			if layout.columnNumber(header) == 0 {
+				return fmt.Errorf("column %s not found in sheet %s", header, sheet)
+			}
+			switch aggregate {
+			case AggregateSum, AggregateAverage, AggregateCount, AggregateCountA, AggregateMin, AggregateMax:
+			default:
+				return fmt.Errorf("unsupported aggregate %s", aggregate)
+			}
+		}
+		if layout.rows <= layout.headerRows {
+			continue // no data
+		}
+		for i, column := range layout.columns {
+			cellName, err := layout.cellName(i+1, layout.rows+1)
+			if err != nil {
+				return err
+			}
+			if err = setCellStyle(f, sheet, cellName, cellStyle{border: options.tableBorders}, options); err != nil {
+				return err
+			}
+			aggregate, ok := spec[column.header]
+			if !ok {
+				if i == 0 {
+					if err = f.SetCellValue(sheet, cellName, summaryLabel); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			rangeRef, _, err := columnDataRange(sheet, column.header, options)
+			if err != nil {
+				return err
+			}
+			if err = f.SetCellFormula(sheet, cellName, string(aggregate)+"("+rangeRef+")"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package excelorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Sheet32 struct {
+	Name   string  `excel_header:"name"`
+	Count  int     `excel_header:"count"`
+	Amount float64 `excel_header:"amount"`
+}
+
+func (Sheet32) SheetName() string {
+	return "sheet32"
+}
+
+func TestWithSummaryRow(t *testing.T) {
+	models := []SheetModel{
+		Sheet32{Name: "foo", Count: 1, Amount: 1.5},
+		Sheet32{Name: "bar", Count: 2, Amount: 2.5},
+		Sheet32{Name: "baz", Count: 6, Amount: 3.5},
+	}
+	f, err := write(models, WithFloatAsNumber(""), WithSummaryRow("sheet32", map[string]Aggregate{
+		"count":  AggregateSum,
+		"amount": AggregateAverage,
+	}), WithAutoFilter())
+	require.NoError(t, err)
+	assert.Equal(t, "Total", getCellValue(t, f, "sheet32", "A5"))
+	formula, err := f.GetCellFormula("sheet32", "B5")
+	require.NoError(t, err)
+	assert.Equal(t, "SUM(B2:B4)", formula)
+	formula, err = f.GetCellFormula("sheet32", "C5")
+	require.NoError(t, err)
+	assert.Equal(t, "AVERAGE(C2:C4)", formula)
+	value, err := f.CalcCellValue("sheet32", "B5")
+	require.NoError(t, err)
+	assert.Equal(t, "9", value)
+	value, err = f.CalcCellValue("sheet32", "C5")
+	require.NoError(t, err)
+	assert.Equal(t, "2.5", value)
+	assert.Equal(t, "'sheet32'!$A$1:$C$4", f.GetDefinedName()[0].RefersTo) // summary row is not filtered
+
+	f, err = write(models, WithSummaryRow("sheet32", map[string]Aggregate{"name": AggregateCountA}))
+	require.NoError(t, err)
+	formula, err = f.GetCellFormula("sheet32", "A5")
+	require.NoError(t, err)
+	assert.Equal(t, "COUNTA(A2:A4)", formula)
+
+	f, err = write(nil, WithSheetHeaders(Sheet32{}), WithSummaryRow("sheet32", map[string]Aggregate{"count": AggregateSum}))
+	require.NoError(t, err)
+	assert.Len(t, getRows(t, f, "sheet32"), 1) // no data
+
+	_, err = write(models, WithSummaryRow("sheet32", map[string]Aggregate{"price": AggregateSum}))
+	require.EqualError(t, err, "column price not found in sheet sheet32")
+	_, err = write(models, WithSummaryRow("sheet32", map[string]Aggregate{"count": "MEDIAN"}))
+	require.EqualError(t, err, "unsupported aggregate MEDIAN")
+	_, err = write(models, WithSummaryRow("sheet33", map[string]Aggregate{"count": AggregateSum}))
+	require.EqualError(t, err, "sheet sheet33 not found")
+}
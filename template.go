@@ -0,0 +1,119 @@
+package excelorm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// placeholderRegexp matches placeholders such as {{FieldName}}
+var placeholderRegexp = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+// WithPlaceholders 将模板中形如 {{FieldName}} 的占位符替换为 data 中对应的值, 如封面, 参数单元格,
+// data 为 struct(按字段名匹配, 可以为指针)或 map[string]interface{}, 不存在的占位符保持不变,
+// 经过值为 nil 的嵌入指针的字段展示为 WithIfNullValue 设置的空值;
+// 单元格内容仅为一个占位符时按值的类型写入(如整数, 格式同数据单元格, 如按 WithTimeFormatLayout 格式化的时间), 否则替换为文本
+func WithPlaceholders(data interface{}) Option {
+	return func(options *options) {
+		options.placeholders = data
+	}
+}
+
+// replacePlaceholders replaces placeholders in all cells of f with values of options.placeholders
+func replacePlaceholders(f *excelize.File, options *options) error {
+	if options.placeholders == nil {
+		return nil
+	}
+	for _, sheet := range f.GetSheetList() {
+		rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return err
+		}
+		for i, row := range rows {
+			for j, text := range row {
+				if !strings.Contains(text, "{{") {
+					continue
+				}
+				value, ok, err := replacePlaceholder(text, options)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				cellName, err := coordinatesToCellName(j+1, i+1)
+				if err != nil {
+					return err
+				}
+				if err = f.SetCellValue(sheet, cellName, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// replacePlaceholder returns text with its placeholders replaced, it returns false if nothing is replaced
+func replacePlaceholder(text string, options *options) (interface{}, bool, error) {
+	if match := placeholderRegexp.FindStringSubmatch(text); match != nil && match[0] == text {
+		return placeholderValue(match[1], options) // the whole cell is a placeholder, keep type of value
+	}
+	var replaced bool
+	var err error
+	result := placeholderRegexp.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderRegexp.FindStringSubmatch(placeholder)[1]
+		value, ok, e := placeholderValue(name, options)
+		if e != nil {
+			err = e
+		}
+		if !ok || e != nil {
+			return placeholder
+		}
+		replaced = true
+		return fmt.Sprint(value)
+	})
+	return result, replaced, err
+}
+
+// placeholderValue returns the value of name in options.placeholders formatted like a cell
+func placeholderValue(name string, options *options) (interface{}, bool, error) {
+	data := reflect.ValueOf(options.placeholders)
+	for data.Kind() == reflect.Pointer || data.Kind() == reflect.Interface {
+		if data.IsNil() {
+			return nil, false, nil
+		}
+		data = data.Elem()
+	}
+	var value reflect.Value
+	col := column{header: name}
+	switch data.Kind() {
+	case reflect.Struct:
+		field, ok := data.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return nil, false, nil
+		}
+		if value, ok = fieldByIndex(data, field.Index); !ok {
+			return options.ifNullValue, true, nil // nil embedded pointer on the path
+		}
+		col.field = field
+	case reflect.Map:
+		if data.Type().Key().Kind() != reflect.String {
+			return nil, false, fmt.Errorf("unsupported placeholders type %s", data.Type())
+		}
+		value = data.MapIndex(reflect.ValueOf(name).Convert(data.Type().Key()))
+		if !value.IsValid() {
+			return nil, false, nil
+		}
+	default:
+		return nil, false, fmt.Errorf("unsupported placeholders type %s", data.Type())
+	}
+	formatted, err := formatValue(value, col, options)
+	if err != nil {
+		return nil, false, err
+	}
+	return formatted, true, nil
+}
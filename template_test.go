@@ -0,0 +1,71 @@
+package excelorm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWithPlaceholders(t *testing.T) {
+	f := excelize.NewFile()
+	require.NoError(t, f.SetCellValue("Sheet1", "A1", "Report of {{ Company }}, {{Month}}"))
+	require.NoError(t, f.SetCellValue("Sheet1", "A2", "{{Total}}"))
+	require.NoError(t, f.SetCellValue("Sheet1", "A3", "{{Unknown}}"))
+	require.NoError(t, f.SetCellValue("Sheet1", "A4", "{{Date}}"))
+	path := filepath.Join(t.TempDir(), "template.xlsx")
+	require.NoError(t, f.SaveAs(path))
+
+	type cover struct {
+		Company string
+		Month   string
+		Total   int
+		Date    time.Time
+	}
+	data := &cover{Company: "ACME", Month: "2024-01", Total: 12, Date: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)}
+	for _, placeholders := range []interface{}{data, map[string]interface{}{
+		"Company": data.Company, "Month": data.Month, "Total": data.Total, "Date": data.Date,
+	}} {
+		buffer, err := WriteExcelIntoTemplate(path, []SheetModel{Sheet2{Col1: "foo", Col2: 1}},
+			WithPlaceholders(placeholders), WithTimeFormatLayout("2006/01/02"))
+		require.NoError(t, err)
+		f, err = excelize.OpenReader(buffer)
+		require.NoError(t, err)
+		assert.Equal(t, "Report of ACME, 2024-01", getCellValue(t, f, "Sheet1", "A1"))
+		assert.Equal(t, "12", getCellValue(t, f, "Sheet1", "A2"))
+		cellType, err := f.GetCellType("Sheet1", "A2")
+		require.NoError(t, err)
+		assert.NotEqual(t, excelize.CellTypeSharedString, cellType)
+		assert.Equal(t, "{{Unknown}}", getCellValue(t, f, "Sheet1", "A3"))
+		assert.Equal(t, "2024/01/31", getCellValue(t, f, "Sheet1", "A4"))
+	}
+
+	// fields promoted through a nil embedded pointer are null
+	type Author struct {
+		Name string
+	}
+	type signedCover struct {
+		*Author
+		Company string
+	}
+	f = excelize.NewFile()
+	require.NoError(t, f.SetCellValue("Sheet1", "A5", "{{Name}}"))
+	path = filepath.Join(t.TempDir(), "signed.xlsx")
+	require.NoError(t, f.SaveAs(path))
+	buffer, err := WriteExcelIntoTemplate(path, nil, WithPlaceholders(signedCover{Company: "ACME"}), WithIfNullValue("-"))
+	require.NoError(t, err)
+	f, err = excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, "-", getCellValue(t, f, "Sheet1", "A5"))
+	buffer, err = WriteExcelIntoTemplate(path, nil, WithPlaceholders(signedCover{Author: &Author{Name: "foo"}}))
+	require.NoError(t, err)
+	f, err = excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", getCellValue(t, f, "Sheet1", "A5"))
+
+	_, err = WriteExcelIntoTemplate(path, nil, WithPlaceholders([]string{"foo"}))
+	assert.EqualError(t, err, "unsupported placeholders type []string")
+}
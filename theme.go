@@ -0,0 +1,65 @@
+package excelorm
+
+import "github.com/xuri/excelize/v2"
+
+// Theme 样式主题, 包括表头样式, 隔行填充, 边框和字体, 见 WithTheme
+type Theme struct {
+	HeaderStyle    *excelize.Style // 表头单元格的样式, 为 nil 时为默认样式, 不为 nil 时不能与 WithHeadless 一起使用
+	ZebraFillColor string          // 隔行填充的背景色, 见 WithZebraStripes, 为空时不填充
+	Borders        bool            // 是否添加边框, 见 WithTableBorders
+	BorderStyle    int             // 边框的线条样式
+	BorderColor    string          // 边框的颜色
+	FontName       string          // 默认字体, 见 WithDefaultFont
+	FontSize       float64         // 默认字号
+}
+
+var (
+	// ThemeMinimal 加粗的表头和浅灰色的底部边框线条, 无隔行填充
+	ThemeMinimal = Theme{
+		HeaderStyle: &excelize.Style{
+			Font:   &excelize.Font{Bold: true},
+			Border: []excelize.Border{{Type: "bottom", Color: "#808080", Style: 1}},
+		},
+	}
+	// ThemeCorporateBlue 蓝底白字的表头, 浅蓝色隔行填充和细边框
+	ThemeCorporateBlue = Theme{
+		HeaderStyle: &excelize.Style{
+			Font:      &excelize.Font{Bold: true, Color: "#FFFFFF"},
+			Fill:      excelize.Fill{Type: "pattern", Color: []string{"#1F4E78"}, Pattern: 1},
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		},
+		ZebraFillColor: "#DDEBF7",
+		Borders:        true,
+		BorderStyle:    1,
+		BorderColor:    "#9BC2E6",
+	}
+	// ThemeDark 黑底白字的表头, 灰色隔行填充和深灰色细边框
+	ThemeDark = Theme{
+		HeaderStyle: &excelize.Style{
+			Font:      &excelize.Font{Bold: true, Color: "#FFFFFF"},
+			Fill:      excelize.Fill{Type: "pattern", Color: []string{"#262626"}, Pattern: 1},
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		},
+		ZebraFillColor: "#D9D9D9",
+		Borders:        true,
+		BorderStyle:    1,
+		BorderColor:    "#404040",
+	}
+)
+
+// WithTheme 使用样式主题, 如 ThemeCorporateBlue, 一个选项即可得到美观的表格,
+// 在其后使用的 WithZebraStripes, WithTableBorders, WithDefaultFont 覆盖主题中对应的设置
+func WithTheme(theme Theme) Option {
+	return func(options *options) {
+		options.headerStyle = theme.HeaderStyle
+		options.zebraFillColor = theme.ZebraFillColor
+		options.tableBorders = theme.Borders
+		options.borderStyle = theme.BorderStyle
+		if options.tableBorders && options.borderStyle == 0 {
+			options.borderStyle = 1
+		}
+		options.borderColor = theme.BorderColor
+		options.fontName = theme.FontName
+		options.fontSize = theme.FontSize
+	}
+}
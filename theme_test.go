@@ -0,0 +1,49 @@
+package excelorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTheme(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithTheme(ThemeCorporateBlue), WithSheetHeaders(Sheet29{}))
+	require.NoError(t, err)
+	header, err := f.GetStyle(getCellStyle(t, f, "sheet30", "A1"))
+	require.NoError(t, err)
+	assert.True(t, header.Font.Bold)
+	assert.Equal(t, []string{"1F4E78"}, trimColors(header.Fill.Color))
+	assert.Len(t, header.Border, 4)
+	assert.Equal(t, getCellStyle(t, f, "sheet30", "A1"), getCellStyle(t, f, "sheet29", "B1"))
+	style, err := f.GetStyle(getCellStyle(t, f, "sheet30", "A3"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DDEBF7"}, trimColors(style.Fill.Color))
+	assert.Equal(t, "9BC2E6", strings.TrimPrefix(style.Border[0].Color, "#"))
+
+	// options after theme override it
+	f, err = write(models, WithTheme(ThemeDark), WithZebraStripes(""), WithDefaultFont("Arial", 10))
+	require.NoError(t, err)
+	style, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A3"))
+	require.NoError(t, err)
+	assert.Empty(t, style.Fill.Color)
+	assert.Equal(t, "Arial", style.Font.Family)
+	header, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A1"))
+	require.NoError(t, err)
+	assert.Equal(t, "Arial", header.Font.Family)
+	assert.Equal(t, "FFFFFF", strings.TrimPrefix(header.Font.Color, "#"))
+
+	f, err = write(models, WithTheme(ThemeMinimal))
+	require.NoError(t, err)
+	header, err = f.GetStyle(getCellStyle(t, f, "sheet30", "A1"))
+	require.NoError(t, err)
+	assert.True(t, header.Font.Bold)
+	require.Len(t, header.Border, 1)
+	assert.Equal(t, "bottom", header.Border[0].Type)
+	assert.Zero(t, getCellStyle(t, f, "sheet30", "A3"))
+}
@@ -0,0 +1,162 @@
+package excelorm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ValidationType WithColumnValidation 的校验类型
+type ValidationType string
+
+const (
+	ValidationWhole      ValidationType = "whole"      // 整数
+	ValidationDecimal    ValidationType = "decimal"    // 小数
+	ValidationDate       ValidationType = "date"       // 日期
+	ValidationTextLength ValidationType = "textLength" // 文本长度
+	ValidationCustom     ValidationType = "custom"     // 自定义公式
+)
+
+var validationTypes = map[ValidationType]excelize.DataValidationType{
+	ValidationWhole:      excelize.DataValidationTypeWhole,
+	ValidationDecimal:    excelize.DataValidationTypeDecimal,
+	ValidationDate:       excelize.DataValidationTypeDate,
+	ValidationTextLength: excelize.DataValidationTypeTextLength,
+}
+
+// ValidationRule WithColumnValidation 的校验规则
+type ValidationRule struct {
+	Type         ValidationType // 校验类型
+	Min          interface{}    // 最小值(包含), int, float64, time.Time 或公式, 为 nil 时不限制, 不用于 ValidationCustom
+	Max          interface{}    // 最大值(包含), 同 Min
+	Formula      string         // ValidationCustom 的公式, 结果为 TRUE 时有效, 如 `ISNUMBER(SEARCH("@",C2))`, 引用相对于第一个数据单元格
+	AllowBlank   bool           // 是否允许空单元格
+	Prompt       string         // 选中单元格时的提示
+	ErrorTitle   string         // 输入无效时提示的标题
+	ErrorMessage string         // 输入无效时提示的内容, 默认为 excel 的提示
+}
+
+// columnValidation is a validation set by WithColumnValidation
+type columnValidation struct {
+	sheet  string
+	header string
+	rule   ValidationRule
+}
+
+// WithColumnValidation 为 sheet 中表头为 header 的列添加数据校验, 限制数值或日期的范围, 文本的长度, 或者自定义公式,
+// 校验区域为表头以下的所有单元格(包括数据行以下的空白单元格), 用于可以重新导入的模板, 输入无效时拒绝输入; 不支持流式写入
+// example usage:
+//
+//	excelorm.WithColumnValidation("orders", "amount", excelorm.ValidationRule{Type: excelorm.ValidationDecimal, Min: 0.0})
+//	excelorm.WithColumnValidation("orders", "remark", excelorm.ValidationRule{Type: excelorm.ValidationTextLength, Max: 200, AllowBlank: true})
+func WithColumnValidation(sheet, header string, rule ValidationRule) Option {
+	return func(options *options) {
+		options.columnValidations = append(options.columnValidations,
+			columnValidation{sheet: sanitizeSheetName(sheet), header: header, rule: rule})
+	}
+}
+
+// addColumnValidations adds the data validations set by WithColumnValidation
+func addColumnValidations(f *excelize.File, options *options) error {
+	for _, validation := range options.columnValidations {
+		layout, ok := options.sheetLayouts[validation.sheet]
+		if !ok {
+			return fmt.Errorf("sheet %s not found", validation.sheet)
+		}
+		col := layout.columnNumber(validation.header)
+		if col == 0 {
+			return fmt.Errorf("column %s not found in sheet %s", validation.header, validation.sheet)
+		}
+		firstCell, err := layout.cellName(col, layout.headerRows+1)
+		if err != nil {
+			return err
+		}
+		colName, err := columnNumberToName(layout.col(col))
+		if err != nil {
+			return err
+		}
+		dv, err := newDataValidation(validation.rule)
+		if err != nil {
+			return fmt.Errorf("validation of column %s in sheet %s: %w", validation.header, validation.sheet, err)
+		}
+		dv.Sqref = firstCell + ":" + colName + strconv.Itoa(excelize.TotalRows)
+		if err = f.AddDataValidation(validation.sheet, dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formulaEscaper escapes formulas of data validations, excelize writes them as inner XML
+var formulaEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// newDataValidation converts rule to the data validation of excelize, without the range
+func newDataValidation(rule ValidationRule) (*excelize.DataValidation, error) {
+	dv := excelize.NewDataValidation(rule.AllowBlank)
+	if rule.Type == ValidationCustom {
+		if rule.Formula == "" {
+			return nil, errors.New("formula of custom validation is empty")
+		}
+		dv.Type, dv.Formula1 = string(ValidationCustom), rule.Formula
+	} else {
+		validationType, ok := validationTypes[rule.Type]
+		if !ok {
+			return nil, fmt.Errorf("unsupported validation type %s", rule.Type)
+		}
+		lower, err := validationBound(rule.Min)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := validationBound(rule.Max)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case lower != "" && upper != "":
+			err = dv.SetRange(lower, upper, validationType, excelize.DataValidationOperatorBetween)
+		case lower != "":
+			err = dv.SetRange(lower, "", validationType, excelize.DataValidationOperatorGreaterThanOrEqual)
+		case upper != "":
+			err = dv.SetRange(upper, "", validationType, excelize.DataValidationOperatorLessThanOrEqual)
+		default:
+			return nil, errors.New("both Min and Max are nil")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	dv.Formula1, dv.Formula2 = formulaEscaper.Replace(dv.Formula1), formulaEscaper.Replace(dv.Formula2)
+	if rule.Prompt != "" {
+		dv.SetInput("", rule.Prompt)
+	}
+	dv.SetError(excelize.DataValidationErrorStyleStop, rule.ErrorTitle, rule.ErrorMessage)
+	return dv, nil
+}
+
+// validationBound returns the formula of the bound of a validation, or an empty string if bound is nil
+func validationBound(bound interface{}) (string, error) {
+	switch bound := bound.(type) {
+	case nil:
+		return "", nil
+	case int:
+		return strconv.Itoa(bound), nil
+	case float64:
+		return strconv.FormatFloat(bound, 'f', -1, 64), nil
+	case time.Time:
+		formula := fmt.Sprintf("DATE(%d,%d,%d)", bound.Year(), bound.Month(), bound.Day())
+		if hour, minute, sec := bound.Clock(); hour != 0 || minute != 0 || sec != 0 {
+			formula += fmt.Sprintf("+TIME(%d,%d,%d)", hour, minute, sec)
+		}
+		return formula, nil
+	case string:
+		if bound == "" {
+			return "", errors.New("bound is empty")
+		}
+		return bound, nil
+	}
+	return "", fmt.Errorf("unsupported bound type %T", bound)
+}
@@ -0,0 +1,85 @@
+package excelorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWithColumnValidation(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models,
+		WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationDecimal, Min: -100.5, Max: 100, ErrorMessage: "out of range"}),
+		WithColumnValidation("sheet30", "name", ValidationRule{Type: ValidationTextLength, Max: 10, AllowBlank: true, Prompt: "at most 10 characters"}),
+		WithColumnValidation("sheet30", "name", ValidationRule{Type: ValidationDate, Min: time.Date(2024, 1, 31, 12, 30, 0, 0, time.UTC)}),
+		WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationCustom, Formula: "MOD(B2,1)=0"}),
+	)
+	require.NoError(t, err)
+	validations, err := f.GetDataValidations("sheet30")
+	require.NoError(t, err)
+	require.Len(t, validations, 4)
+	assert.Equal(t, "B2:B1048576", validations[0].Sqref)
+	assert.Equal(t, "decimal", validations[0].Type)
+	assert.Equal(t, "between", validations[0].Operator)
+	assert.Equal(t, "-100.5", validations[0].Formula1)
+	assert.Equal(t, "100", validations[0].Formula2)
+	assert.True(t, validations[0].ShowErrorMessage)
+	assert.Equal(t, "out of range", *validations[0].Error)
+	assert.False(t, validations[0].AllowBlank)
+	assert.Equal(t, "A2:A1048576", validations[1].Sqref)
+	assert.Equal(t, "textLength", validations[1].Type)
+	assert.Equal(t, "lessThanOrEqual", validations[1].Operator)
+	assert.Equal(t, "10", validations[1].Formula1)
+	assert.True(t, validations[1].AllowBlank)
+	assert.Equal(t, "at most 10 characters", *validations[1].Prompt)
+	assert.Equal(t, "greaterThanOrEqual", validations[2].Operator)
+	assert.Equal(t, "DATE(2024,1,31)+TIME(12,30,0)", validations[2].Formula1)
+	assert.Equal(t, "custom", validations[3].Type)
+	assert.Equal(t, "MOD(B2,1)=0", validations[3].Formula1)
+
+	f, err = write(models, WithSheetTitle("sheet30", "accounts", nil),
+		WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationWhole, Min: 0}))
+	require.NoError(t, err)
+	validations, err = f.GetDataValidations("sheet30")
+	require.NoError(t, err)
+	require.Len(t, validations, 1)
+	assert.Equal(t, "B3:B1048576", validations[0].Sqref)
+
+	// formulas are escaped in the worksheet XML
+	f, err = write(models,
+		WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationCustom, Formula: "AND(B2>0,B2<100)"}),
+		WithColumnValidation("sheet30", "name", ValidationRule{Type: ValidationTextLength, Max: `LEN("a&b")`}))
+	require.NoError(t, err)
+	buffer, err := f.WriteToBuffer()
+	require.NoError(t, err)
+	reopened, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer reopened.Close()
+	validations, err = reopened.GetDataValidations("sheet30")
+	require.NoError(t, err)
+	require.Len(t, validations, 2)
+	assert.Equal(t, "AND(B2>0,B2<100)", validations[0].Formula1)
+	assert.Equal(t, `LEN("a&b")`, validations[1].Formula1)
+
+	_, err = write(models, WithColumnValidation("sheet31", "balance", ValidationRule{Type: ValidationWhole, Min: 0}))
+	require.EqualError(t, err, "sheet sheet31 not found")
+	_, err = write(models, WithColumnValidation("sheet30", "amount", ValidationRule{Type: ValidationWhole, Min: 0}))
+	require.EqualError(t, err, "column amount not found in sheet sheet30")
+	_, err = write(models, WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationWhole}))
+	require.EqualError(t, err, "validation of column balance in sheet sheet30: both Min and Max are nil")
+	_, err = write(models, WithColumnValidation("sheet30", "balance", ValidationRule{Type: "list", Min: 0}))
+	require.EqualError(t, err, "validation of column balance in sheet sheet30: unsupported validation type list")
+	_, err = write(models, WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationCustom}))
+	require.EqualError(t, err, "validation of column balance in sheet sheet30: formula of custom validation is empty")
+	_, err = write(models, WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationWhole, Min: int64(0)}))
+	require.EqualError(t, err, "validation of column balance in sheet sheet30: unsupported bound type int64")
+	_, err = write(models, WithColumnValidation("sheet30", "balance", ValidationRule{Type: ValidationWhole, Min: 0}),
+		WithTempFileThreshold(0))
+	require.EqualError(t, err, "WithColumnValidation is not supported with WithTempFileThreshold")
+}
@@ -0,0 +1,274 @@
+package excelorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// docProperties is the document properties set by WithDocProperties
+type docProperties struct {
+	title   string
+	author  string
+	company string
+	subject string
+	created time.Time
+}
+
+// WithDocProperties 工作簿的文档属性: 标题, 作者, 公司, 主题和创建时间, 以便读取文档属性的合规, 审计系统识别生成的文件,
+// 为空或零值的属性不设置
+func WithDocProperties(title, author, company, subject string, created time.Time) Option {
+	return func(options *options) {
+		options.docProperties = &docProperties{
+			title:   title,
+			author:  author,
+			company: company,
+			subject: subject,
+			created: created,
+		}
+	}
+}
+
+// setDocProperties applies properties set by WithDocProperties to f
+func setDocProperties(f *excelize.File, options *options) error {
+	props := options.docProperties
+	if props == nil {
+		return nil
+	}
+	docProps, err := f.GetDocProps()
+	if err != nil {
+		return err
+	}
+	if props.title != "" {
+		docProps.Title = props.title
+	}
+	if props.author != "" {
+		docProps.Creator = props.author
+		docProps.LastModifiedBy = props.author
+	}
+	if props.subject != "" {
+		docProps.Subject = props.subject
+	}
+	if !props.created.IsZero() {
+		docProps.Created = props.created.UTC().Format(time.RFC3339)
+		docProps.Modified = docProps.Created
+	}
+	if err = f.SetDocProps(docProps); err != nil {
+		return err
+	}
+	if props.company == "" {
+		return nil
+	}
+	appProps, err := f.GetAppProps()
+	if err != nil {
+		return err
+	}
+	appProps.Company = props.company
+	return f.SetAppProps(appProps)
+}
+
+// WithActiveSheet 打开工作簿时显示的 sheet, 如汇总 sheet, 默认为第一个 sheet
+func WithActiveSheet(sheet string) Option {
+	return func(options *options) {
+		options.activeSheet = sanitizeSheetName(sheet)
+	}
+}
+
+// WithHiddenSheets 隐藏 sheets, 如内部使用的查找表, 不能隐藏 WithActiveSheet 指定的 sheet 或全部 sheet
+func WithHiddenSheets(sheets ...string) Option {
+	return func(options *options) {
+		options.hiddenSheets = sanitizeSheetNames(sheets)
+	}
+}
+
+// setSheetVisibility sets the active sheet and hides sheets, it is called after the default sheet is deleted
+func setSheetVisibility(f *excelize.File, options *options) error {
+	for _, sheet := range append([]string{options.activeSheet}, options.hiddenSheets...) {
+		if _, ok := options.sheetLayouts[sheet]; sheet != "" && !ok {
+			return fmt.Errorf("sheet %s not found", sheet)
+		}
+	}
+	if len(options.hiddenSheets) == 0 && options.activeSheet == "" {
+		return nil
+	}
+	activeSheet := options.activeSheet
+	if activeSheet == "" { // the first visible sheet
+		for _, sheet := range f.GetSheetList() {
+			if !containsString(options.hiddenSheets, sheet) {
+				activeSheet = sheet
+				break
+			}
+		}
+		if activeSheet == "" {
+			return errors.New("can not hide all sheets")
+		}
+	} else if containsString(options.hiddenSheets, activeSheet) {
+		return fmt.Errorf("active sheet %s can not be hidden", activeSheet)
+	}
+	index, err := f.GetSheetIndex(activeSheet)
+	if err != nil {
+		return err
+	}
+	f.SetActiveSheet(index)
+	for _, sheet := range options.hiddenSheets {
+		if err = f.SetSheetVisible(sheet, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definedName is a defined name set by WithDefinedName
+type definedName struct {
+	name  string
+	sheet string
+}
+
+// WithDefinedName 将 sheet 的数据区域(包括表头行, 不包括标题行和汇总行)注册为工作簿范围的名称 name,
+// 以便在公式或 Power Query 中通过名称引用数据
+func WithDefinedName(name, sheet string) Option {
+	return func(options *options) {
+		options.definedNames = append(options.definedNames, definedName{name: name, sheet: sanitizeSheetName(sheet)})
+	}
+}
+
+// setDefinedNames registers data ranges of sheets set by WithDefinedName
+func setDefinedNames(f *excelize.File, options *options) error {
+	for _, definedName := range options.definedNames {
+		layout, ok := options.sheetLayouts[definedName.sheet]
+		if !ok {
+			return fmt.Errorf("sheet %s not found", definedName.sheet)
+		}
+		firstRow := layout.headerRows // last header row
+		if firstRow == layout.titleRows {
+			firstRow++ // headless
+		}
+		if firstRow > layout.rows || len(layout.columns) == 0 {
+			continue // no data
+		}
+		hCell, err := layout.cellName(1, firstRow)
+		if err != nil {
+			return err
+		}
+		vCell, err := layout.cellName(len(layout.columns), layout.rows)
+		if err != nil {
+			return err
+		}
+		rangeRef, err := absoluteRange(hCell + ":" + vCell)
+		if err != nil {
+			return err
+		}
+		if err = f.SetDefinedName(&excelize.DefinedName{
+			Name:     definedName.name,
+			RefersTo: fmt.Sprintf("'%s'!%s", definedName.sheet, rangeRef),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hyperlinkStyle is the style of the hyperlinks to sheets, such as the links of WithSummarySheet
+var hyperlinkStyle = &excelize.Style{Font: &excelize.Font{Color: "#0563C1", Underline: "single"}}
+
+// summarySheetRow is a row of the sheet set by WithSummarySheet
+type summarySheetRow struct {
+	sheet string `excel_header:"-"`
+	Sheet string `excel_header:"sheet"`
+	Rows  int    `excel_header:"rows"`
+}
+
+func (r summarySheetRow) SheetName() string {
+	return r.sheet
+}
+
+// WithSummarySheet 生成名为 name 的第一个 sheet, 列出每个数据 sheet 的名称和数据行数, 名称链接到对应的 sheet,
+// 不包括 WithHiddenSheets 隐藏的 sheet, 没有数据 sheet 时不生成; 未设置 WithActiveSheet 时打开工作簿显示该 sheet
+func WithSummarySheet(name string) Option {
+	return func(options *options) {
+		options.summarySheet = sanitizeSheetName(name)
+	}
+}
+
+// addSummarySheet adds the sheet set by WithSummarySheet before all sheets, it is called after the data sheets are written
+func addSummarySheet(f *excelize.File, options *options) error {
+	name := options.summarySheet
+	if name == "" {
+		return nil
+	}
+	if _, ok := options.sheetLayouts[name]; ok {
+		return fmt.Errorf("summary sheet %s already exists", name)
+	}
+	var rows []summarySheetRow
+	for _, sheet := range f.GetSheetList() {
+		layout, ok := options.sheetLayouts[sheet]
+		if ok && !containsString(options.hiddenSheets, sheet) {
+			rows = append(rows, summarySheetRow{sheet: name, Sheet: sheet, Rows: layout.dataRows})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	summaryOptions := options.generatedSheetOptions()
+	summaryOptions.streaming = false // the stream writers of data sheets are already flushed
+	for _, row := range rows {
+		if err := appendRow(f, name, row, summaryOptions); err != nil {
+			return err
+		}
+	}
+	layout := options.sheetLayouts[name]
+	col := layout.columnNumber("sheet")
+	for i, row := range rows {
+		cell, err := layout.cellName(col, layout.headerRows+i+1)
+		if err != nil {
+			return err
+		}
+		target, err := options.sheetLayouts[row.Sheet].cellName(1, 1)
+		if err != nil {
+			return err
+		}
+		if err = f.SetCellHyperLink(name, cell, chartRef(row.Sheet, target), "Location"); err != nil {
+			return err
+		}
+		if err = setCellStyle(f, name, cell, cellStyle{border: options.tableBorders, custom: hyperlinkStyle}, options); err != nil {
+			return err
+		}
+	}
+	if err := f.MoveSheet(name, f.GetSheetName(0)); err != nil {
+		return err
+	}
+	f.SetActiveSheet(0) // changed by setSheetVisibility if WithActiveSheet is set
+	return nil
+}
+
+// defaultBackLinkText is the text of the links set by WithBackLinks
+const defaultBackLinkText = "← back to index"
+
+// WithBackLinks 在每个 sheet 的表头行右侧(空一列)写入链接到 WithSummarySheet 生成的 sheet 的单元格, 文本为 text,
+// 为空时为 "← back to index", 冻结表头时始终可见; 链接为 HYPERLINK 公式, 支持流式写入, 没有表头的 sheet 不写入
+func WithBackLinks(text string) Option {
+	return func(options *options) {
+		if text == "" {
+			text = defaultBackLinkText
+		}
+		options.backLinkText = text
+	}
+}
+
+// backLink returns the formula cell linking to the summary sheet, which is written in the header row of layout
+func backLink(sheetName string, layout *sheetLayout, row int, options *options) (excelize.Cell, bool) {
+	if options.backLinkText == "" || sheetName == options.summarySheet || row != layout.titleRows+1 {
+		return excelize.Cell{}, false
+	}
+	target := "#" + chartRef(options.summarySheet, "A1")
+	formula := fmt.Sprintf("HYPERLINK(%s,%s)", quoteFormulaString(target), quoteFormulaString(options.backLinkText))
+	return excelize.Cell{Value: options.backLinkText, Formula: formula}, true
+}
+
+// quoteFormulaString returns s as a string literal of formulas
+func quoteFormulaString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
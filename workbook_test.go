@@ -0,0 +1,153 @@
+package excelorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWithDocProperties(t *testing.T) {
+	models := []SheetModel{Sheet30{Name: "foo", Balance: -1}}
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("CST", 8*3600))
+	f, err := write(models, WithDocProperties("Monthly Report", "excelorm", "VarusHsu", "orders", created))
+	require.NoError(t, err)
+	docProps, err := f.GetDocProps()
+	require.NoError(t, err)
+	assert.Equal(t, "Monthly Report", docProps.Title)
+	assert.Equal(t, "excelorm", docProps.Creator)
+	assert.Equal(t, "orders", docProps.Subject)
+	assert.Equal(t, "2024-01-02T07:04:05Z", docProps.Created)
+	appProps, err := f.GetAppProps()
+	require.NoError(t, err)
+	assert.Equal(t, "VarusHsu", appProps.Company)
+
+	f, err = write(models, WithDocProperties("Monthly Report", "", "", "", time.Time{}))
+	require.NoError(t, err)
+	docProps, err = f.GetDocProps()
+	require.NoError(t, err)
+	assert.Equal(t, "Monthly Report", docProps.Title)
+	assert.Equal(t, "xuri", docProps.Creator) // default of excelize
+}
+
+func TestSheetVisibility(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: -1},
+		Sheet2{Col1: "foo"},
+		Sheet32{Name: "foo"},
+	}
+	f, err := write(models, WithActiveSheet("sheet2"), WithHiddenSheets("sheet32"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.GetActiveSheetIndex())
+	visible, err := f.GetSheetVisible("sheet32")
+	require.NoError(t, err)
+	assert.False(t, visible)
+
+	f, err = write(models, WithHiddenSheets("sheet30"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.GetActiveSheetIndex()) // the first visible sheet
+	visible, err = f.GetSheetVisible("sheet30")
+	require.NoError(t, err)
+	assert.False(t, visible)
+
+	_, err = write(models, WithActiveSheet("sheet2"), WithHiddenSheets("sheet2"))
+	require.EqualError(t, err, "active sheet sheet2 can not be hidden")
+	_, err = write(models, WithHiddenSheets("sheet30", "sheet2", "sheet32"))
+	require.EqualError(t, err, "can not hide all sheets")
+	_, err = write(models, WithActiveSheet("sheet31"))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+func TestWithDefinedName(t *testing.T) {
+	models := []SheetModel{
+		Sheet30{Name: "foo", Balance: 1},
+		Sheet30{Name: "bar", Balance: 2},
+	}
+	f, err := write(models, WithDefinedName("accounts", "sheet30"), WithDefinedName("empty", "sheet29"),
+		WithSheetHeaders(Sheet29{}), WithSheetTitle("sheet30", "title", nil),
+		WithSummaryRow("sheet30", map[string]Aggregate{"balance": AggregateSum}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Workbook accounts": "'sheet30'!$A$2:$B$4",
+		"Workbook empty":    "'sheet29'!$A$1:$C$1",
+	}, definedNames(f))
+
+	f, err = write(models, WithHeadless(), WithDefinedName("accounts", "sheet30"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Workbook accounts": "'sheet30'!$A$1:$B$2"}, definedNames(f))
+
+	_, err = write(models, WithDefinedName("accounts", "sheet31"))
+	require.EqualError(t, err, "sheet sheet31 not found")
+}
+
+func TestWithSummarySheet(t *testing.T) {
+	models := []SheetModel{Sheet30{Name: "foo"}, Sheet30{Name: "bar"}, Sheet1{Col1: "baz"}, Sheet40{Name: "qux"}}
+	for _, threshold := range []int64{-1, 0} {
+		f, err := write(models, WithSummarySheet("index"), WithHiddenSheets("sheet40"), WithTempFileThreshold(threshold))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"index", "sheet1", "sheet30", "sheet40"}, f.GetSheetList()) // sheet1 is the default sheet
+		assert.Equal(t, 0, f.GetActiveSheetIndex())
+		assert.Equal(t, [][]string{{"sheet", "rows"}, {"sheet1", "1"}, {"sheet30", "2"}}, getRows(t, f, "index"))
+		ok, target, err := f.GetCellHyperLink("index", "A3")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "'sheet30'!$A$1", target)
+	}
+
+	f, err := write(models, WithSummarySheet("index"), WithActiveSheet("sheet1"))
+	require.NoError(t, err)
+	assert.Equal(t, "sheet1", f.GetSheetName(f.GetActiveSheetIndex()))
+
+	// no row numbers in the summary sheet
+	f, err = write(models, WithSummarySheet("index"), WithRowNumbers("no"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"sheet", "rows"}, {"sheet1", "1"}, {"sheet30", "2"}, {"sheet40", "1"}}, getRows(t, f, "index"))
+	assert.Equal(t, "no", getCellValue(t, f, "sheet30", "A1"))
+	ok, target, err := f.GetCellHyperLink("index", "A3")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "'sheet30'!$A$1", target)
+
+	// options of data rows are not applied to the summary sheet
+	grey := &excelize.Style{Fill: excelize.Fill{Type: "pattern", Color: []string{"#D9D9D9"}, Pattern: 1}}
+	f, err = write(models[:2], WithSummarySheet("index"), WithValueMapping("sheet", map[interface{}]string{"sheet30": "accounts"}),
+		WithRowStyleFunc(func(sheet string, row int, model SheetModel) *excelize.Style {
+			if model.(Sheet30).Balance < 0 {
+				return grey
+			}
+			return nil
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"sheet", "rows"}, {"sheet30", "2"}}, getRows(t, f, "index"))
+
+	// rows of the header blocks are not counted
+	f, err = write([]SheetModel{Sheet30{Name: "foo"}, Sheet34{Sheet: "sheet30", Name: "bar"}, Sheet30{Name: "baz"}},
+		WithSummarySheet("index"), WithSheetCollisionPolicy(SheetCollisionNewHeader))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"sheet", "rows"}, {"sheet30", "3"}}, getRows(t, f, "index"))
+
+	_, err = write(models, WithSummarySheet("sheet30"))
+	assert.EqualError(t, err, "summary sheet sheet30 already exists")
+}
+
+func TestWithBackLinks(t *testing.T) {
+	models := []SheetModel{Sheet30{Name: "foo"}, Sheet40{Name: "bar"}}
+	for _, threshold := range []int64{-1, 0} {
+		f, err := write(models, WithSummarySheet("index"), WithBackLinks(""), WithSheetTitle("sheet40", "Bar", nil),
+			WithTempFileThreshold(threshold))
+		require.NoError(t, err)
+		assert.Equal(t, "← back to index", getCellValue(t, f, "sheet30", "D1"))
+		formula, err := f.GetCellFormula("sheet30", "D1")
+		require.NoError(t, err)
+		assert.Equal(t, `HYPERLINK("#'index'!$A$1","← back to index")`, formula)
+		assert.Equal(t, "← back to index", getCellValue(t, f, "sheet40", "E2")) // below the title
+		assert.Equal(t, [][]string{{"sheet", "rows"}, {"sheet30", "1"}, {"sheet40", "1"}}, getRows(t, f, "index"))
+	}
+
+	var optionErrs OptionErrors
+	_, err := write(models, WithBackLinks("back"))
+	require.ErrorAs(t, err, &optionErrs)
+	assert.EqualError(t, optionErrs[0], "WithBackLinks can not be used without WithSummarySheet")
+}
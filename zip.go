@@ -0,0 +1,61 @@
+package excelorm
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WriteWorkbooksZip 生成多个 excel 并打包为一个 zip 写入 w, 如每个客户一个 excel, workbooks 的键为 zip 中的文件名(如 "customers/foo.xlsx"),
+// 按文件名排序写入, 每个 excel 直接写入 zip, 不经过临时文件; options 同时作用于所有 excel,
+// 跳过的行见 WithContinueOnError, 此时继续写入其它 excel, 返回包含文件名的第一个 RowErrors
+// example usage:
+//
+//	workbooks := map[string][]excelorm.SheetModel{
+//		"foo.xlsx": fooOrders,
+//		"bar.xlsx": barOrders,
+//	}
+//	err := excelorm.WriteWorkbooksZip(w, workbooks)
+func WriteWorkbooksZip(w io.Writer, workbooks map[string][]SheetModel, opts ...Option) error {
+	names := make([]string, 0, len(workbooks))
+	for name := range workbooks {
+		if name == "" {
+			return ErrEmptyFileName
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	archive := zip.NewWriter(w)
+	var rowErr error
+	for _, name := range names {
+		err := writeZipEntry(archive, name, workbooks[name], opts...)
+		if err != nil && !isRowErrors(err) {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err != nil && rowErr == nil {
+			rowErr = fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return rowErr
+}
+
+// writeZipEntry writes the workbook of sheetModels to the file name in archive
+func writeZipEntry(archive *zip.Writer, name string, sheetModels []SheetModel, opts ...Option) error {
+	f, err := write(sheetModels, opts...)
+	if err != nil && !isRowErrors(err) {
+		return err
+	}
+	defer f.Close() // remove temp files of stream writers
+	entry, createErr := archive.Create(name)
+	if createErr != nil {
+		return createErr
+	}
+	if _, writeErr := f.WriteTo(entry); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package excelorm
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteWorkbooksZip(t *testing.T) {
+	var buffer bytes.Buffer
+	workbooks := map[string][]SheetModel{
+		"customers/foo.xlsx": {Sheet30{Name: "foo"}},
+		"customers/bar.xlsx": {Sheet30{Name: "bar"}},
+	}
+	require.NoError(t, WriteWorkbooksZip(&buffer, workbooks))
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	for i, name := range []string{"bar", "foo"} {
+		assert.Equal(t, "customers/"+name+".xlsx", archive.File[i].Name)
+		entry, err := archive.File[i].Open()
+		require.NoError(t, err)
+		f, err := excelize.OpenReader(entry)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "balance"}, {name, "0.00"}}, getRows(t, f, "sheet30"))
+		require.NoError(t, entry.Close())
+	}
+
+	var rowErrors RowErrors
+	workbooks["customers/baz.xlsx"] = []SheetModel{nil, Sheet30{Name: "baz"}}
+	err = WriteWorkbooksZip(&bytes.Buffer{}, workbooks, WithContinueOnError())
+	require.ErrorAs(t, err, &rowErrors)
+	assert.Contains(t, err.Error(), "customers/baz.xlsx")
+	assert.ErrorIs(t, WriteWorkbooksZip(&bytes.Buffer{}, map[string][]SheetModel{"": nil}), ErrEmptyFileName)
+}